@@ -3,4 +3,7 @@ package api
 const (
 	UsageOperatorDomain              = "usage.services.openmcp.cloud"
 	UsageOperatorPlatformServiceName = "provider." + UsageOperatorDomain
+
+	// UsageFinalizer is set on every ManagedControlPlane, to make sure its deletion is captured by the usage-operator.
+	UsageFinalizer = UsageOperatorDomain + "/usage"
 )
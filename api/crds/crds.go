@@ -2,14 +2,84 @@ package crds
 
 import (
 	"embed"
+	"fmt"
 
 	crdutil "github.com/openmcp-project/controller-utils/pkg/crds"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 )
 
+// MCPUsageCRDName is the name of the MCPUsage CRD, which is served in multiple versions.
+const MCPUsageCRDName = "mcpusages.usage.openmcp.cloud"
+
+// ChargingTargetCRDName is the name of the ChargingTarget CRD.
+const ChargingTargetCRDName = "chargingtargets.usage.openmcp.cloud"
+
+// MCPUsageMonthlyCRDName is the name of the MCPUsageMonthly CRD.
+const MCPUsageMonthlyCRDName = "mcpusagemonthlies.usage.openmcp.cloud"
+
 //go:embed manifests
 var CRDFS embed.FS
 
 func CRDs() ([]*apiextv1.CustomResourceDefinition, error) {
 	return crdutil.CRDsFromFileSystem(CRDFS, "manifests")
 }
+
+// ConfigureConversion sets the conversion webhook of a CRD with multiple versions. The existing CRD is the one installed
+// in the cluster, or nil. Without an url, a webhook, which is configured in the existing CRD, is kept. Only if there is
+// none, only the storage version is served, as the other versions can't be converted. This is refused, as long as
+// objects are still stored in another version, as they can't be read without the webhook anymore.
+func ConfigureConversion(crd, existing *apiextv1.CustomResourceDefinition, url string, caBundle []byte) error {
+	if url == "" && existing != nil && existing.Spec.Conversion != nil && existing.Spec.Conversion.Strategy == apiextv1.WebhookConverter {
+		crd.Spec.Conversion = existing.Spec.Conversion.DeepCopy()
+		return nil
+	}
+
+	if url == "" {
+		if stored := OutdatedStoredVersions(crd, existing); len(stored) > 0 {
+			return fmt.Errorf("objects of %s are still stored as %v, which can't be read without the conversion webhook: "+
+				"configure the conversion webhook and migrate the objects with the migrate-storage command first", crd.Name, stored)
+		}
+		crd.Spec.Conversion = &apiextv1.CustomResourceConversion{Strategy: apiextv1.NoneConverter}
+		for i := range crd.Spec.Versions {
+			crd.Spec.Versions[i].Served = crd.Spec.Versions[i].Storage
+		}
+		return nil
+	}
+
+	crd.Spec.Conversion = &apiextv1.CustomResourceConversion{
+		Strategy: apiextv1.WebhookConverter,
+		Webhook: &apiextv1.WebhookConversion{
+			ClientConfig: &apiextv1.WebhookClientConfig{
+				URL:      &url,
+				CABundle: caBundle,
+			},
+			ConversionReviewVersions: []string{"v1"},
+		},
+	}
+	return nil
+}
+
+// OutdatedStoredVersions returns the versions, in which objects of the existing CRD are still stored, other than the
+// storage version of the crd.
+func OutdatedStoredVersions(crd, existing *apiextv1.CustomResourceDefinition) []string {
+	if existing == nil {
+		return nil
+	}
+	var outdated []string
+	for _, version := range existing.Status.StoredVersions {
+		if version != StorageVersion(crd) {
+			outdated = append(outdated, version)
+		}
+	}
+	return outdated
+}
+
+// StorageVersion returns the name of the storage version of the CRD.
+func StorageVersion(crd *apiextv1.CustomResourceDefinition) string {
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			return version.Name
+		}
+	}
+	return ""
+}
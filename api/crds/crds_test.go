@@ -0,0 +1,69 @@
+package crds
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+var _ = Describe("ConfigureConversion", func() {
+	var crd *apiextv1.CustomResourceDefinition
+
+	BeforeEach(func() {
+		all, err := CRDs()
+		Expect(err).ToNot(HaveOccurred())
+		for _, c := range all {
+			if c.Name == MCPUsageCRDName {
+				crd = c
+			}
+		}
+		Expect(crd).ToNot(BeNil())
+		Expect(StorageVersion(crd)).Should(Equal("v2"))
+	})
+
+	served := func() map[string]bool {
+		versions := map[string]bool{}
+		for _, version := range crd.Spec.Versions {
+			versions[version.Name] = version.Served
+		}
+		return versions
+	}
+
+	It("should configure the webhook and serve all versions", func() {
+		Expect(ConfigureConversion(crd, nil, "https://usage-operator.example.com/convert", []byte("ca"))).To(Succeed())
+
+		Expect(crd.Spec.Conversion.Strategy).Should(Equal(apiextv1.WebhookConverter))
+		Expect(*crd.Spec.Conversion.Webhook.ClientConfig.URL).Should(Equal("https://usage-operator.example.com/convert"))
+		Expect(served()).Should(Equal(map[string]bool{"v1": true, "v2": true}))
+	})
+
+	It("should only serve the storage version without a webhook on a new installation", func() {
+		Expect(ConfigureConversion(crd, nil, "", nil)).To(Succeed())
+
+		Expect(crd.Spec.Conversion.Strategy).Should(Equal(apiextv1.NoneConverter))
+		Expect(served()).Should(Equal(map[string]bool{"v1": false, "v2": true}))
+	})
+
+	It("should keep the webhook of the existing CRD", func() {
+		existing := crd.DeepCopy()
+		Expect(ConfigureConversion(existing, nil, "https://usage-operator.example.com/convert", nil)).To(Succeed())
+		existing.Status.StoredVersions = []string{"v1", "v2"}
+
+		Expect(ConfigureConversion(crd, existing, "", nil)).To(Succeed())
+
+		Expect(crd.Spec.Conversion).Should(Equal(existing.Spec.Conversion))
+		Expect(served()).Should(Equal(map[string]bool{"v1": true, "v2": true}))
+	})
+
+	It("should refuse to remove the webhook, while objects are stored in another version", func() {
+		existing := crd.DeepCopy()
+		existing.Spec.Conversion = &apiextv1.CustomResourceConversion{Strategy: apiextv1.NoneConverter}
+		existing.Status.StoredVersions = []string{"v1"}
+
+		Expect(ConfigureConversion(crd, existing, "", nil)).To(MatchError(ContainSubstring("migrate-storage")))
+
+		existing.Status.StoredVersions = []string{"v2"}
+		Expect(ConfigureConversion(crd, existing, "", nil)).To(Succeed())
+		Expect(served()).Should(Equal(map[string]bool{"v1": false, "v2": true}))
+	})
+})
@@ -0,0 +1,16 @@
+package v1
+
+import (
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+)
+
+// The constants are shared by all versions of the API and are defined in the storage version.
+const (
+	RetentionAnnotation = v2.RetentionAnnotation
+	MCPUIDLabel         = v2.MCPUIDLabel
+	MCPPhasePending     = v2.MCPPhasePending
+
+	ConditionChargingTargetResolved = v2.ConditionChargingTargetResolved
+	ConditionUsageCurrent           = v2.ConditionUsageCurrent
+	ConditionMCPPresent             = v2.ConditionMCPPresent
+)
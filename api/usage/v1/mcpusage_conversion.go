@@ -0,0 +1,190 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+)
+
+// hubFieldsAnnotation preserves the fields of the hub version, which can't be represented in v1, so they survive a
+// round trip through v1.
+const hubFieldsAnnotation = "usage.openmcp.cloud/v2-fields"
+
+// hubFields are the fields of the hub version, which v1 doesn't have.
+type hubFields struct {
+	ChargingTargetSplit   []v2.ChargingTargetShare      `json:"charging_target_split,omitempty"`
+	ChargingTargetRule    string                        `json:"charging_target_rule,omitempty"`
+	ChargingTargetHistory []v2.ChargingTargetAssignment `json:"charging_target_history,omitempty"`
+	// ChargingTargets contains the split of the daily usage by charging target, keyed by the date of the day.
+	ChargingTargets map[string][]v2.ChargingTargetUsage `json:"charging_targets,omitempty"`
+	// ContentHashes and ReportContentHashes contain the content hashes of the daily usage and of the reports, keyed by
+	// the date of the day.
+	ContentHashes       map[string]string `json:"content_hashes,omitempty"`
+	ReportContentHashes map[string]string `json:"report_content_hashes,omitempty"`
+}
+
+func dayKey(date metav1.Time) string {
+	return date.UTC().Format("2006-01-02T15:04:05Z")
+}
+
+// ConvertTo converts this MCPUsage to the hub version v2. The computed fields of the spec are moved to the status.
+func (src *MCPUsage) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v2.MCPUsage)
+	if !ok {
+		return fmt.Errorf("unexpected hub type %T", dstRaw)
+	}
+
+	var fields hubFields
+	dst.ObjectMeta = src.ObjectMeta
+	if raw, ok := src.Annotations[hubFieldsAnnotation]; ok {
+		if err := json.Unmarshal([]byte(raw), &fields); err != nil {
+			return fmt.Errorf("can't parse %s annotation: %w", hubFieldsAnnotation, err)
+		}
+		dst.Annotations = maps.Clone(src.Annotations)
+		delete(dst.Annotations, hubFieldsAnnotation)
+	}
+	dst.Spec = v2.MCPUsageSpec{
+		Project:   src.Spec.Project,
+		Workspace: src.Spec.Workspace,
+		MCP:       src.Spec.MCP,
+	}
+
+	status := v2.UsageOperatorStatus{
+		ChargingTarget:     src.Spec.ChargingTarget,
+		ChargingTargetType: src.Spec.ChargingTargetType,
+		MCPUID:             src.Spec.MCPUID,
+		LastUsageCaptured:  src.Spec.LastUsageCaptured,
+		MCPCreatedAt:       src.Spec.MCPCreatedAt,
+		MCPDeletedAt:       src.Spec.MCPDeletedAt,
+		MCPPhase:           src.Spec.MCPPhase,
+		BillingTimezone:    src.Spec.BillingTimezone,
+		Message:            src.Spec.Message,
+
+		ChargingTargetSplit:   fields.ChargingTargetSplit,
+		ChargingTargetRule:    fields.ChargingTargetRule,
+		ChargingTargetHistory: fields.ChargingTargetHistory,
+	}
+	for _, usage := range src.Spec.Usage {
+		status.Usage = append(status.Usage, v2.DailyUsage{
+			Date:             usage.Date,
+			Usage:            usage.Usage,
+			NonBillableUsage: usage.NonBillableUsage,
+			ChargingTargets:  fields.ChargingTargets[dayKey(usage.Date)],
+			ContentHash:      fields.ContentHashes[dayKey(usage.Date)],
+		})
+	}
+	for _, interval := range src.Spec.Lifecycle {
+		status.Lifecycle = append(status.Lifecycle, v2.LifecycleInterval(interval))
+	}
+	if src.Status.UsageOperator != nil {
+		status.ObservedGeneration = src.Status.UsageOperator.ObservedGeneration
+		status.Conditions = append([]metav1.Condition(nil), src.Status.UsageOperator.Conditions...)
+	}
+	dst.Status.UsageOperator = status
+
+	dst.Status.DailyUsageReport = nil
+	for _, report := range src.Status.DailyUsageReport {
+		dst.Status.DailyUsageReport = append(dst.Status.DailyUsageReport, v2.DailyUsageReport{
+			Date:        report.Date,
+			Status:      v2.ReportStatus(report.Status),
+			Message:     report.Message,
+			ContentHash: fields.ReportContentHashes[dayKey(report.Date)],
+		})
+	}
+
+	return nil
+}
+
+// ConvertFrom converts the hub version v2 to this version.
+func (dst *MCPUsage) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v2.MCPUsage)
+	if !ok {
+		return fmt.Errorf("unexpected hub type %T", srcRaw)
+	}
+
+	status := src.Status.UsageOperator
+	fields := hubFields{
+		ChargingTargetSplit:   status.ChargingTargetSplit,
+		ChargingTargetRule:    status.ChargingTargetRule,
+		ChargingTargetHistory: status.ChargingTargetHistory,
+	}
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = MCPUsageSpec{
+		ChargingTarget:     status.ChargingTarget,
+		ChargingTargetType: status.ChargingTargetType,
+		Project:            src.Spec.Project,
+		Workspace:          src.Spec.Workspace,
+		MCP:                src.Spec.MCP,
+		MCPUID:             status.MCPUID,
+		LastUsageCaptured:  status.LastUsageCaptured,
+		MCPCreatedAt:       status.MCPCreatedAt,
+		MCPDeletedAt:       status.MCPDeletedAt,
+		MCPPhase:           status.MCPPhase,
+		BillingTimezone:    status.BillingTimezone,
+		Message:            status.Message,
+	}
+	for _, usage := range status.Usage {
+		dst.Spec.Usage = append(dst.Spec.Usage, DailyUsage{
+			Date:             usage.Date,
+			Usage:            usage.Usage,
+			NonBillableUsage: usage.NonBillableUsage,
+		})
+		if len(usage.ChargingTargets) > 0 {
+			if fields.ChargingTargets == nil {
+				fields.ChargingTargets = map[string][]v2.ChargingTargetUsage{}
+			}
+			fields.ChargingTargets[dayKey(usage.Date)] = usage.ChargingTargets
+		}
+		if usage.ContentHash != "" {
+			if fields.ContentHashes == nil {
+				fields.ContentHashes = map[string]string{}
+			}
+			fields.ContentHashes[dayKey(usage.Date)] = usage.ContentHash
+		}
+	}
+	for _, report := range src.Status.DailyUsageReport {
+		if report.ContentHash != "" {
+			if fields.ReportContentHashes == nil {
+				fields.ReportContentHashes = map[string]string{}
+			}
+			fields.ReportContentHashes[dayKey(report.Date)] = report.ContentHash
+		}
+	}
+	if !reflect.ValueOf(fields).IsZero() {
+		raw, err := json.Marshal(fields)
+		if err != nil {
+			return fmt.Errorf("can't marshal %s annotation: %w", hubFieldsAnnotation, err)
+		}
+		dst.Annotations = maps.Clone(src.Annotations)
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[hubFieldsAnnotation] = string(raw)
+	}
+	for _, interval := range status.Lifecycle {
+		dst.Spec.Lifecycle = append(dst.Spec.Lifecycle, LifecycleInterval(interval))
+	}
+
+	dst.Status = MCPUsageStatus{}
+	if status.ObservedGeneration != 0 || len(status.Conditions) > 0 {
+		dst.Status.UsageOperator = &UsageOperatorStatus{
+			ObservedGeneration: status.ObservedGeneration,
+			Conditions:         append([]metav1.Condition(nil), status.Conditions...),
+		}
+	}
+	for _, report := range src.Status.DailyUsageReport {
+		dst.Status.DailyUsageReport = append(dst.Status.DailyUsageReport, DailyUsageReport{
+			Date:    report.Date,
+			Status:  string(report.Status),
+			Message: report.Message,
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package v1
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+)
+
+func date(d int) metav1.Time {
+	return metav1.NewTime(time.Date(2025, 7, d, 0, 0, 0, 0, time.UTC))
+}
+
+func hours(h int) metav1.Duration {
+	return metav1.Duration{Duration: time.Duration(h) * time.Hour}
+}
+
+// hub returns an MCPUsage, which uses all fields of v2.
+func hub() *v2.MCPUsage {
+	return &v2.MCPUsage{
+		ObjectMeta: metav1.ObjectMeta{Name: "usage", Labels: map[string]string{"app": "usage"}, Annotations: map[string]string{"note": "kept"}},
+		Spec:       v2.MCPUsageSpec{Project: "project", Workspace: "workspace", MCP: "mcp"},
+		Status: v2.MCPUsageStatus{
+			UsageOperator: v2.UsageOperatorStatus{
+				ObservedGeneration: 3,
+				Conditions: []metav1.Condition{{
+					Type: "ChargingTargetResolved", Status: metav1.ConditionTrue, Reason: "Resolved", Message: "resolved",
+					ObservedGeneration: 3, LastTransitionTime: date(2),
+				}},
+				ChargingTarget:      "cc-1:75,cc-2:25",
+				ChargingTargetType:  "cost-center",
+				ChargingTargetSplit: []v2.ChargingTargetShare{{ChargingTarget: "cc-1", Weight: 75}, {ChargingTarget: "cc-2", Weight: 25}},
+				ChargingTargetRule:  "default",
+				ChargingTargetHistory: []v2.ChargingTargetAssignment{
+					{ChargingTarget: "cc-0", ChargingTargetType: "cost-center", EffectiveFrom: date(1)},
+					{ChargingTarget: "cc-1:75,cc-2:25", ChargingTargetType: "cost-center", EffectiveFrom: date(2),
+						Split: []v2.ChargingTargetShare{{ChargingTarget: "cc-1", Weight: 75}, {ChargingTarget: "cc-2", Weight: 25}}},
+				},
+				MCPUID: "uid",
+				Usage: []v2.DailyUsage{
+					{Date: date(1), Usage: hours(24), ContentHash: "hash-1",
+						ChargingTargets: []v2.ChargingTargetUsage{{ChargingTarget: "cc-0", ChargingTargetType: "cost-center", Usage: hours(24)}}},
+					{Date: date(2), Usage: hours(8), NonBillableUsage: hours(2), ContentHash: "hash-2",
+						ChargingTargets: []v2.ChargingTargetUsage{
+							{ChargingTarget: "cc-1", ChargingTargetType: "cost-center", Usage: hours(6), NonBillableUsage: hours(2)},
+							{ChargingTarget: "cc-2", ChargingTargetType: "cost-center", Usage: hours(2)},
+						}},
+				},
+				LastUsageCaptured: date(2),
+				MCPCreatedAt:      date(1),
+				Lifecycle:         []v2.LifecycleInterval{{CreatedAt: date(1), DeletedAt: date(2), UID: "old"}, {CreatedAt: date(2), UID: "uid"}},
+				MCPPhase:          "Ready",
+				BillingTimezone:   "Europe/Berlin",
+				Message:           "ok",
+			},
+			DailyUsageReport: []v2.DailyUsageReport{
+				{Date: date(1), Status: v2.ReportStatusReported, ContentHash: "hash-1"},
+				{Date: date(2), Status: v2.ReportStatusFailed, Message: "failed"},
+			},
+		},
+	}
+}
+
+var _ = Describe("MCPUsage conversion", func() {
+	It("should keep all fields on a round trip from v2 through v1", func() {
+		src := hub()
+
+		var spoke MCPUsage
+		Expect(spoke.ConvertFrom(src)).To(Succeed())
+		Expect(spoke.Spec.ChargingTarget).Should(Equal("cc-1:75,cc-2:25"))
+		Expect(spoke.Spec.Usage).Should(HaveLen(2))
+		Expect(spoke.Annotations).Should(HaveKey(hubFieldsAnnotation))
+
+		var dst v2.MCPUsage
+		Expect(spoke.ConvertTo(&dst)).To(Succeed())
+		// the times of the annotation are parsed in the local time zone, so they are compared by their instant
+		Expect(&dst).Should(BeComparableTo(src))
+	})
+
+	It("should keep all fields on a round trip from v1 through v2", func() {
+		var src MCPUsage
+		Expect(src.ConvertFrom(hub())).To(Succeed())
+
+		var h v2.MCPUsage
+		Expect(src.ConvertTo(&h)).To(Succeed())
+		Expect(h.Annotations).ShouldNot(HaveKey(hubFieldsAnnotation))
+
+		var dst MCPUsage
+		Expect(dst.ConvertFrom(&h)).To(Succeed())
+		Expect(dst).Should(Equal(src))
+	})
+
+	It("should convert a v1 MCPUsage without the annotation", func() {
+		src := MCPUsage{
+			ObjectMeta: metav1.ObjectMeta{Name: "usage"},
+			Spec: MCPUsageSpec{
+				ChargingTarget: "cc-1", ChargingTargetType: "cost-center", Project: "project", Workspace: "workspace", MCP: "mcp",
+				Usage: []DailyUsage{{Date: date(1), Usage: hours(4)}},
+			},
+			Status: MCPUsageStatus{DailyUsageReport: []DailyUsageReport{{Date: date(1), Status: "Reported"}}},
+		}
+
+		var h v2.MCPUsage
+		Expect(src.ConvertTo(&h)).To(Succeed())
+		Expect(h.Status.UsageOperator.ChargingTarget).Should(Equal("cc-1"))
+		Expect(h.Status.UsageOperator.Usage).Should(Equal([]v2.DailyUsage{{Date: date(1), Usage: hours(4)}}))
+		Expect(h.Status.DailyUsageReport).Should(Equal([]v2.DailyUsageReport{{Date: date(1), Status: v2.ReportStatusReported}}))
+
+		var dst MCPUsage
+		Expect(dst.ConvertFrom(&h)).To(Succeed())
+		Expect(dst).Should(Equal(src))
+	})
+
+	It("should fail on a corrupt annotation", func() {
+		var src MCPUsage
+		Expect(src.ConvertFrom(hub())).To(Succeed())
+		src.Annotations[hubFieldsAnnotation] = "{not json"
+
+		var dst v2.MCPUsage
+		Expect(src.ConvertTo(&dst)).To(MatchError(ContainSubstring(hubFieldsAnnotation)))
+	})
+})
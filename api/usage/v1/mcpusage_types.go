@@ -22,6 +22,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -29,15 +30,28 @@ import (
 
 // MCPUsageSpec defines the desired state of MCPUsage.
 type MCPUsageSpec struct {
-	ChargingTarget     string       `json:"charging_target"`
-	ChargingTargetType string       `json:"charging_target_type"`
-	Project            string       `json:"project"`
-	Workspace          string       `json:"workspace"`
-	MCP                string       `json:"mcp"`
-	Usage              []DailyUsage `json:"daily_usage,omitempty"`
-	LastUsageCaptured  metav1.Time  `json:"last_usage_captured,omitempty"`
-	MCPCreatedAt       metav1.Time  `json:"mcp_created_at,omitempty"`
-	MCPDeletedAt       metav1.Time  `json:"mcp_deleted_at,omitempty"`
+	ChargingTarget     string `json:"charging_target"`
+	ChargingTargetType string `json:"charging_target_type"`
+	Project            string `json:"project"`
+	Workspace          string `json:"workspace"`
+	MCP                string `json:"mcp"`
+	// MCPUID is the uid of the current incarnation of the MCP.
+	MCPUID            types.UID    `json:"mcp_uid,omitempty"`
+	Usage             []DailyUsage `json:"daily_usage,omitempty"`
+	LastUsageCaptured metav1.Time  `json:"last_usage_captured,omitempty"`
+	// MCPCreatedAt and MCPDeletedAt are the creation and deletion time of the current incarnation of the MCP.
+	MCPCreatedAt metav1.Time `json:"mcp_created_at,omitempty"`
+	MCPDeletedAt metav1.Time `json:"mcp_deleted_at,omitempty"`
+	// Lifecycle contains every interval in which an MCP with this name existed, oldest first.
+	// The last interval is the current one. Usage is only captured within these intervals.
+	Lifecycle []LifecycleInterval `json:"lifecycle,omitempty"`
+	// MCPPhase is the status of the MCP as last observed by the usage-operator. It determines whether the time since
+	// the last capture is billable. An empty phase is billable, as it is only found on resources which were created
+	// before the phase was tracked.
+	MCPPhase string `json:"mcp_phase,omitempty"`
+	// BillingTimezone is the IANA timezone, which determines the day boundaries of the daily usage.
+	// If empty, the default billing timezone of the usage-operator is used.
+	BillingTimezone string `json:"billing_timezone,omitempty"`
 
 	Message string `json:"message,omitempty"`
 }
@@ -46,7 +60,21 @@ type MCPUsageSpec struct {
 type MCPUsageStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
-	DailyUsageReport []DailyUsageReport `json:"daily_usage_report"`
+	// DailyUsageReport is owned by the metering operator. It is optional, so the usage-operator can write its status
+	// before the metering operator reported anything.
+	// +optional
+	DailyUsageReport []DailyUsageReport `json:"daily_usage_report,omitempty"`
+	// UsageOperator is owned by the usage-operator. Metering operators must not modify it.
+	UsageOperator *UsageOperatorStatus `json:"usage_operator,omitempty"`
+}
+
+// UsageOperatorStatus is the part of the status, which is written by the usage-operator.
+type UsageOperatorStatus struct {
+	// ObservedGeneration is the generation of the MCPUsage, which was last written by the usage-operator.
+	ObservedGeneration int64 `json:"observed_generation,omitempty"`
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 type DailyUsageReport struct {
@@ -55,9 +83,20 @@ type DailyUsageReport struct {
 	Message string      `json:"message,omitempty"`
 }
 
+// LifecycleInterval is the time between the creation and the deletion of one incarnation of an MCP.
+type LifecycleInterval struct {
+	CreatedAt metav1.Time `json:"created_at"`
+	// DeletedAt is empty as long as the MCP exists.
+	DeletedAt metav1.Time `json:"deleted_at,omitempty"`
+	// UID is the uid of the ManagedControlPlane.
+	UID types.UID `json:"uid,omitempty"`
+}
+
 type DailyUsage struct {
 	Date  metav1.Time     `json:"date"`
 	Usage metav1.Duration `json:"usage"`
+	// NonBillableUsage is the time of the day in which the MCP was in a non-billable phase.
+	NonBillableUsage metav1.Duration `json:"non_billable_usage,omitempty"`
 }
 
 func NewDailyUsage(date time.Time, hours int) (DailyUsage, error) {
@@ -0,0 +1,14 @@
+package v1
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestV1(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Usage v1 Suite")
+}
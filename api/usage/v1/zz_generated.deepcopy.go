@@ -5,6 +5,7 @@
 package v1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -13,6 +14,7 @@ func (in *DailyUsage) DeepCopyInto(out *DailyUsage) {
 	*out = *in
 	in.Date.DeepCopyInto(&out.Date)
 	out.Usage = in.Usage
+	out.NonBillableUsage = in.NonBillableUsage
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DailyUsage.
@@ -41,6 +43,23 @@ func (in *DailyUsageReport) DeepCopy() *DailyUsageReport {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LifecycleInterval) DeepCopyInto(out *LifecycleInterval) {
+	*out = *in
+	in.CreatedAt.DeepCopyInto(&out.CreatedAt)
+	in.DeletedAt.DeepCopyInto(&out.DeletedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LifecycleInterval.
+func (in *LifecycleInterval) DeepCopy() *LifecycleInterval {
+	if in == nil {
+		return nil
+	}
+	out := new(LifecycleInterval)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MCPUsage) DeepCopyInto(out *MCPUsage) {
 	*out = *in
@@ -113,6 +132,13 @@ func (in *MCPUsageSpec) DeepCopyInto(out *MCPUsageSpec) {
 	in.LastUsageCaptured.DeepCopyInto(&out.LastUsageCaptured)
 	in.MCPCreatedAt.DeepCopyInto(&out.MCPCreatedAt)
 	in.MCPDeletedAt.DeepCopyInto(&out.MCPDeletedAt)
+	if in.Lifecycle != nil {
+		in, out := &in.Lifecycle, &out.Lifecycle
+		*out = make([]LifecycleInterval, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPUsageSpec.
@@ -135,6 +161,11 @@ func (in *MCPUsageStatus) DeepCopyInto(out *MCPUsageStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.UsageOperator != nil {
+		in, out := &in.UsageOperator, &out.UsageOperator
+		*out = new(UsageOperatorStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPUsageStatus.
@@ -146,3 +177,25 @@ func (in *MCPUsageStatus) DeepCopy() *MCPUsageStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UsageOperatorStatus) DeepCopyInto(out *UsageOperatorStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UsageOperatorStatus.
+func (in *UsageOperatorStatus) DeepCopy() *UsageOperatorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UsageOperatorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,62 @@
+package v2
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// ChargingTargetSpec describes a charging target, to which usage can be billed.
+type ChargingTargetSpec struct {
+	// Type is the type of the charging target. If set, MCPs must use the same charging target type.
+	// +optional
+	Type string `json:"type,omitempty"`
+	// ValidFrom is the time from which on the charging target can be used. If empty, it is valid from the beginning.
+	// +optional
+	ValidFrom *metav1.Time `json:"valid_from,omitempty"`
+	// ValidUntil is the time until which the charging target can be used. If empty, it doesn't expire.
+	// +optional
+	ValidUntil *metav1.Time `json:"valid_until,omitempty"`
+	// +optional
+	Description string `json:"description,omitempty"`
+}
+
+// IsValidAt returns whether the charging target can be used at the given time.
+func (s ChargingTargetSpec) IsValidAt(at time.Time) bool {
+	if s.ValidFrom != nil && at.Before(s.ValidFrom.Time) {
+		return false
+	}
+	return s.ValidUntil == nil || at.Before(s.ValidUntil.Time)
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=ct
+// +kubebuilder:metadata:labels="openmcp.cloud/cluster=onboarding"
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+// +kubebuilder:printcolumn:name="Valid From",type=date,JSONPath=`.spec.valid_from`
+// +kubebuilder:printcolumn:name="Valid Until",type=date,JSONPath=`.spec.valid_until`
+
+// ChargingTarget registers a valid charging target. Its name is the charging target.
+type ChargingTarget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ChargingTargetSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ChargingTargetList contains a list of ChargingTarget.
+type ChargingTargetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ChargingTarget `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(func(scheme *runtime.Scheme) error {
+		scheme.AddKnownTypes(GroupVersion, &ChargingTarget{}, &ChargingTargetList{})
+		return nil
+	})
+}
@@ -0,0 +1,27 @@
+package v2
+
+const (
+	// RetentionAnnotation overrides the garbage collection retention for the DailyUsage entries of a single MCPUsage.
+	// The value must be a duration as understood by time.ParseDuration, e.g. "2208h" for 92 days.
+	RetentionAnnotation = "usage.openmcp.cloud/retention"
+
+	// MCPUIDLabel contains the uid of the ManagedControlPlane, whose usage is currently tracked by the MCPUsage.
+	MCPUIDLabel = "usage.openmcp.cloud/mcp-uid"
+
+	// MonthLabel contains the month of an MCPUsageMonthly, formatted as YYYY-MM.
+	MonthLabel = "usage.openmcp.cloud/month"
+
+	// MCPPhasePending is the phase of MCPs, which have not reported a status yet.
+	MCPPhasePending = "Pending"
+
+	// ConditionChargingTargetResolved is true, if a charging target was found for the MCP.
+	ConditionChargingTargetResolved = "ChargingTargetResolved"
+	// ConditionChargingTargetValid is true, if the charging target is registered by a valid ChargingTarget.
+	// It is only reported, if charging targets are validated.
+	ConditionChargingTargetValid = "ChargingTargetValid"
+	// ConditionUsageCurrent is true, if the usage was captured with the last scheduled capture or is final,
+	// because the MCP was deleted.
+	ConditionUsageCurrent = "UsageCurrent"
+	// ConditionMCPPresent is true, as long as the current incarnation of the MCP exists.
+	ConditionMCPPresent = "MCPPresent"
+)
@@ -0,0 +1,4 @@
+package v2
+
+// Hub marks v2 as the version, all other versions of the MCPUsage are converted to and from.
+func (*MCPUsage) Hub() {}
@@ -0,0 +1,188 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// MCPUsageSpec identifies the MCP, whose usage is tracked.
+type MCPUsageSpec struct {
+	Project   string `json:"project"`
+	Workspace string `json:"workspace"`
+	MCP       string `json:"mcp"`
+}
+
+// MCPUsageStatus defines the observed state of MCPUsage.
+type MCPUsageStatus struct {
+	// UsageOperator is owned by the usage-operator. Metering operators must not modify it.
+	// +optional
+	UsageOperator UsageOperatorStatus `json:"usage_operator,omitempty"`
+	// DailyUsageReport is owned by the metering operator.
+	// +optional
+	DailyUsageReport []DailyUsageReport `json:"daily_usage_report,omitempty"`
+}
+
+// UsageOperatorStatus contains everything the usage-operator computes for an MCP.
+type UsageOperatorStatus struct {
+	// ObservedGeneration is the generation of the MCPUsage, which was last written by the usage-operator.
+	ObservedGeneration int64 `json:"observed_generation,omitempty"`
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ChargingTarget and ChargingTargetType are the currently active charging target. The charging target is either a
+	// single charging target or a weighted list like "cc-1:70,cc-2:30".
+	ChargingTarget     string `json:"charging_target,omitempty"`
+	ChargingTargetType string `json:"charging_target_type,omitempty"`
+	// ChargingTargetSplit contains the shares of a weighted charging target. It is empty for a single charging target.
+	ChargingTargetSplit []ChargingTargetShare `json:"charging_target_split,omitempty"`
+	// ChargingTargetRule is the name of the rule, which derived the charging target. It is empty, if the charging
+	// target was set explicitly.
+	ChargingTargetRule string `json:"charging_target_rule,omitempty"`
+	// ChargingTargetHistory contains every charging target, which was assigned to the MCP, oldest first. The last
+	// assignment is the currently active one.
+	ChargingTargetHistory []ChargingTargetAssignment `json:"charging_target_history,omitempty"`
+	// MCPUID is the uid of the current incarnation of the MCP.
+	MCPUID            types.UID    `json:"mcp_uid,omitempty"`
+	Usage             []DailyUsage `json:"daily_usage,omitempty"`
+	LastUsageCaptured metav1.Time  `json:"last_usage_captured,omitempty"`
+	// MCPCreatedAt and MCPDeletedAt are the creation and deletion time of the current incarnation of the MCP.
+	MCPCreatedAt metav1.Time `json:"mcp_created_at,omitempty"`
+	MCPDeletedAt metav1.Time `json:"mcp_deleted_at,omitempty"`
+	// Lifecycle contains every interval in which an MCP with this name existed, oldest first.
+	// The last interval is the current one. Usage is only captured within these intervals.
+	Lifecycle []LifecycleInterval `json:"lifecycle,omitempty"`
+	// MCPPhase is the status of the MCP as last observed by the usage-operator. It determines whether the time since
+	// the last capture is billable. An empty phase is billable, as it is only found on resources which were created
+	// before the phase was tracked.
+	MCPPhase string `json:"mcp_phase,omitempty"`
+	// BillingTimezone is the IANA timezone, which determines the day boundaries of the daily usage.
+	// If empty, the default billing timezone of the usage-operator is used.
+	BillingTimezone string `json:"billing_timezone,omitempty"`
+
+	Message string `json:"message,omitempty"`
+}
+
+// ReportStatus is the state of the report of a day by the metering operator.
+// +kubebuilder:validation:Enum=Pending;Reported;Failed;Superseded
+type ReportStatus string
+
+const (
+	// ReportStatusPending means, that the metering operator has seen the day, but not reported it yet.
+	ReportStatusPending ReportStatus = "Pending"
+	// ReportStatusReported acknowledges, that the day was reported with the content hash of the report.
+	ReportStatusReported ReportStatus = "Reported"
+	// ReportStatusFailed means, that the day can't be reported. The message contains the reason.
+	ReportStatusFailed ReportStatus = "Failed"
+	// ReportStatusSuperseded is set by the usage-operator, when the usage of a reported day changed afterwards. The
+	// day needs to be reported again.
+	ReportStatusSuperseded ReportStatus = "Superseded"
+)
+
+// DailyUsageReport is the report of a day by the metering operator.
+type DailyUsageReport struct {
+	Date    metav1.Time  `json:"date"`
+	Status  ReportStatus `json:"status,omitempty"`
+	Message string       `json:"message,omitempty"`
+	// ContentHash is the content hash of the daily usage, which was reported.
+	ContentHash string `json:"content_hash,omitempty"`
+}
+
+// ChargingTargetAssignment assigns the usage from EffectiveFrom on, until the next assignment, to a charging target.
+type ChargingTargetAssignment struct {
+	ChargingTarget     string `json:"charging_target"`
+	ChargingTargetType string `json:"charging_target_type,omitempty"`
+	// Split contains the shares of a weighted charging target.
+	Split         []ChargingTargetShare `json:"split,omitempty"`
+	EffectiveFrom metav1.Time           `json:"effective_from"`
+}
+
+// ChargingTargetShare is the share of one charging target in a weighted charging target.
+type ChargingTargetShare struct {
+	ChargingTarget string `json:"charging_target"`
+	// Weight is the percentage of the usage, which is attributed to the charging target.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Weight int32 `json:"weight"`
+}
+
+// LifecycleInterval is the time between the creation and the deletion of one incarnation of an MCP.
+type LifecycleInterval struct {
+	CreatedAt metav1.Time `json:"created_at"`
+	// DeletedAt is empty as long as the MCP exists.
+	DeletedAt metav1.Time `json:"deleted_at,omitempty"`
+	// UID is the uid of the ManagedControlPlane.
+	UID types.UID `json:"uid,omitempty"`
+}
+
+type DailyUsage struct {
+	Date  metav1.Time     `json:"date"`
+	Usage metav1.Duration `json:"usage"`
+	// NonBillableUsage is the time of the day in which the MCP was in a non-billable phase.
+	NonBillableUsage metav1.Duration `json:"non_billable_usage,omitempty"`
+	// ChargingTargets splits the usage of the day between the charging targets, which were active during that day.
+	ChargingTargets []ChargingTargetUsage `json:"charging_targets,omitempty"`
+	// ContentHash identifies the usage of the day. It changes, whenever the usage or its split changes, so metering
+	// operators can detect changes after they reported the day.
+	ContentHash string `json:"content_hash,omitempty"`
+}
+
+// ChargingTargetUsage is the part of a daily usage, which is attributed to one charging target.
+type ChargingTargetUsage struct {
+	ChargingTarget     string          `json:"charging_target"`
+	ChargingTargetType string          `json:"charging_target_type,omitempty"`
+	Usage              metav1.Duration `json:"usage"`
+	NonBillableUsage   metav1.Duration `json:"non_billable_usage,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=mcpu
+// +kubebuilder:metadata:labels="openmcp.cloud/cluster=onboarding"
+// +kubebuilder:printcolumn:name="Project",type=string,JSONPath=`.spec.project`
+// +kubebuilder:printcolumn:name="Workspace",type=string,JSONPath=`.spec.workspace`
+// +kubebuilder:printcolumn:name="MCP",type=string,JSONPath=`.spec.mcp`
+// +kubebuilder:printcolumn:name="Charging Target",type=string,JSONPath=`.status.usage_operator.charging_target`
+
+// MCPUsage contains the usage of an MCP. The usage-operator writes everything it computes to the status.
+type MCPUsage struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MCPUsageSpec   `json:"spec,omitempty"`
+	Status MCPUsageStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MCPUsageList contains a list of MCPUsage.
+type MCPUsageList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MCPUsage `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(func(scheme *runtime.Scheme) error {
+		scheme.AddKnownTypes(GroupVersion, &MCPUsage{}, &MCPUsageList{})
+		return nil
+	})
+}
@@ -0,0 +1,78 @@
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// MCPUsageMonthlySpec identifies the MCPUsage and the month, whose usage is rolled up.
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable"
+type MCPUsageMonthlySpec struct {
+	Project   string `json:"project"`
+	Workspace string `json:"workspace"`
+	MCP       string `json:"mcp"`
+	// MCPUsage is the name of the MCPUsage, which distinguishes incarnations of an MCP with the same name.
+	MCPUsage string `json:"mcp_usage"`
+	// Month is the calendar month in the billing timezone of the MCPUsage, formatted as YYYY-MM.
+	// +kubebuilder:validation:Pattern=`^[0-9]{4}-(0[1-9]|1[0-2])$`
+	Month string `json:"month"`
+}
+
+// MCPUsageMonthlyStatus contains the usage of the month. It is owned by the usage-operator and can't be changed
+// anymore, once the month is final.
+// +kubebuilder:validation:XValidation:rule="!has(oldSelf.final) || !oldSelf.final || self == oldSelf",message="the usage of a final month is immutable"
+type MCPUsageMonthlyStatus struct {
+	// BillingTimezone is the IANA timezone, which determines the boundaries of the month.
+	BillingTimezone string `json:"billing_timezone,omitempty"`
+	// Usage and NonBillableUsage are the total usage of the month.
+	Usage            metav1.Duration `json:"usage"`
+	NonBillableUsage metav1.Duration `json:"non_billable_usage,omitempty"`
+	// ChargingTargets splits the total usage of the month between the charging targets, which were active during
+	// the month.
+	ChargingTargets []ChargingTargetUsage `json:"charging_targets,omitempty"`
+	// DailyUsage contains the usage of every day of the month, which the MCP existed on, split by charging target.
+	DailyUsage []DailyUsage `json:"daily_usage,omitempty"`
+	// ChargingTargetHistory contains the charging targets, which were active during the month, oldest first.
+	ChargingTargetHistory []ChargingTargetAssignment `json:"charging_target_history,omitempty"`
+	// Final is true, once the month is closed and all of its days were captured. A final month is never changed again.
+	Final bool `json:"final,omitempty"`
+	// FinalizedAt is the time, at which the month became final.
+	FinalizedAt metav1.Time `json:"finalized_at,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=mcpum
+// +kubebuilder:metadata:labels="openmcp.cloud/cluster=onboarding"
+// +kubebuilder:printcolumn:name="Project",type=string,JSONPath=`.spec.project`
+// +kubebuilder:printcolumn:name="Workspace",type=string,JSONPath=`.spec.workspace`
+// +kubebuilder:printcolumn:name="MCP",type=string,JSONPath=`.spec.mcp`
+// +kubebuilder:printcolumn:name="Month",type=string,JSONPath=`.spec.month`
+// +kubebuilder:printcolumn:name="Usage",type=string,JSONPath=`.status.usage`
+// +kubebuilder:printcolumn:name="Final",type=boolean,JSONPath=`.status.final`
+
+// MCPUsageMonthly contains the usage of an MCPUsage in one calendar month. The usage-operator rolls up the daily usage
+// into it before the daily usage is pruned, so it is a durable record for invoicing.
+type MCPUsageMonthly struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MCPUsageMonthlySpec   `json:"spec,omitempty"`
+	Status MCPUsageMonthlyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MCPUsageMonthlyList contains a list of MCPUsageMonthly.
+type MCPUsageMonthlyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MCPUsageMonthly `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(func(scheme *runtime.Scheme) error {
+		scheme.AddKnownTypes(GroupVersion, &MCPUsageMonthly{}, &MCPUsageMonthlyList{})
+		return nil
+	})
+}
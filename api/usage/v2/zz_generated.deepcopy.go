@@ -0,0 +1,459 @@
+//go:build !ignore_autogenerated
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChargingTarget) DeepCopyInto(out *ChargingTarget) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChargingTarget.
+func (in *ChargingTarget) DeepCopy() *ChargingTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(ChargingTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ChargingTarget) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChargingTargetAssignment) DeepCopyInto(out *ChargingTargetAssignment) {
+	*out = *in
+	if in.Split != nil {
+		in, out := &in.Split, &out.Split
+		*out = make([]ChargingTargetShare, len(*in))
+		copy(*out, *in)
+	}
+	in.EffectiveFrom.DeepCopyInto(&out.EffectiveFrom)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChargingTargetAssignment.
+func (in *ChargingTargetAssignment) DeepCopy() *ChargingTargetAssignment {
+	if in == nil {
+		return nil
+	}
+	out := new(ChargingTargetAssignment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChargingTargetList) DeepCopyInto(out *ChargingTargetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ChargingTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChargingTargetList.
+func (in *ChargingTargetList) DeepCopy() *ChargingTargetList {
+	if in == nil {
+		return nil
+	}
+	out := new(ChargingTargetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ChargingTargetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChargingTargetShare) DeepCopyInto(out *ChargingTargetShare) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChargingTargetShare.
+func (in *ChargingTargetShare) DeepCopy() *ChargingTargetShare {
+	if in == nil {
+		return nil
+	}
+	out := new(ChargingTargetShare)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChargingTargetSpec) DeepCopyInto(out *ChargingTargetSpec) {
+	*out = *in
+	if in.ValidFrom != nil {
+		in, out := &in.ValidFrom, &out.ValidFrom
+		*out = (*in).DeepCopy()
+	}
+	if in.ValidUntil != nil {
+		in, out := &in.ValidUntil, &out.ValidUntil
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChargingTargetSpec.
+func (in *ChargingTargetSpec) DeepCopy() *ChargingTargetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ChargingTargetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChargingTargetUsage) DeepCopyInto(out *ChargingTargetUsage) {
+	*out = *in
+	out.Usage = in.Usage
+	out.NonBillableUsage = in.NonBillableUsage
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChargingTargetUsage.
+func (in *ChargingTargetUsage) DeepCopy() *ChargingTargetUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(ChargingTargetUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DailyUsage) DeepCopyInto(out *DailyUsage) {
+	*out = *in
+	in.Date.DeepCopyInto(&out.Date)
+	out.Usage = in.Usage
+	out.NonBillableUsage = in.NonBillableUsage
+	if in.ChargingTargets != nil {
+		in, out := &in.ChargingTargets, &out.ChargingTargets
+		*out = make([]ChargingTargetUsage, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DailyUsage.
+func (in *DailyUsage) DeepCopy() *DailyUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(DailyUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DailyUsageReport) DeepCopyInto(out *DailyUsageReport) {
+	*out = *in
+	in.Date.DeepCopyInto(&out.Date)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DailyUsageReport.
+func (in *DailyUsageReport) DeepCopy() *DailyUsageReport {
+	if in == nil {
+		return nil
+	}
+	out := new(DailyUsageReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LifecycleInterval) DeepCopyInto(out *LifecycleInterval) {
+	*out = *in
+	in.CreatedAt.DeepCopyInto(&out.CreatedAt)
+	in.DeletedAt.DeepCopyInto(&out.DeletedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LifecycleInterval.
+func (in *LifecycleInterval) DeepCopy() *LifecycleInterval {
+	if in == nil {
+		return nil
+	}
+	out := new(LifecycleInterval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPUsage) DeepCopyInto(out *MCPUsage) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPUsage.
+func (in *MCPUsage) DeepCopy() *MCPUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MCPUsage) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPUsageList) DeepCopyInto(out *MCPUsageList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MCPUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPUsageList.
+func (in *MCPUsageList) DeepCopy() *MCPUsageList {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPUsageList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MCPUsageList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPUsageMonthly) DeepCopyInto(out *MCPUsageMonthly) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPUsageMonthly.
+func (in *MCPUsageMonthly) DeepCopy() *MCPUsageMonthly {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPUsageMonthly)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MCPUsageMonthly) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPUsageMonthlyList) DeepCopyInto(out *MCPUsageMonthlyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MCPUsageMonthly, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPUsageMonthlyList.
+func (in *MCPUsageMonthlyList) DeepCopy() *MCPUsageMonthlyList {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPUsageMonthlyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MCPUsageMonthlyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPUsageMonthlySpec) DeepCopyInto(out *MCPUsageMonthlySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPUsageMonthlySpec.
+func (in *MCPUsageMonthlySpec) DeepCopy() *MCPUsageMonthlySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPUsageMonthlySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPUsageMonthlyStatus) DeepCopyInto(out *MCPUsageMonthlyStatus) {
+	*out = *in
+	out.Usage = in.Usage
+	out.NonBillableUsage = in.NonBillableUsage
+	if in.ChargingTargets != nil {
+		in, out := &in.ChargingTargets, &out.ChargingTargets
+		*out = make([]ChargingTargetUsage, len(*in))
+		copy(*out, *in)
+	}
+	if in.DailyUsage != nil {
+		in, out := &in.DailyUsage, &out.DailyUsage
+		*out = make([]DailyUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ChargingTargetHistory != nil {
+		in, out := &in.ChargingTargetHistory, &out.ChargingTargetHistory
+		*out = make([]ChargingTargetAssignment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.FinalizedAt.DeepCopyInto(&out.FinalizedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPUsageMonthlyStatus.
+func (in *MCPUsageMonthlyStatus) DeepCopy() *MCPUsageMonthlyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPUsageMonthlyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPUsageSpec) DeepCopyInto(out *MCPUsageSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPUsageSpec.
+func (in *MCPUsageSpec) DeepCopy() *MCPUsageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPUsageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPUsageStatus) DeepCopyInto(out *MCPUsageStatus) {
+	*out = *in
+	in.UsageOperator.DeepCopyInto(&out.UsageOperator)
+	if in.DailyUsageReport != nil {
+		in, out := &in.DailyUsageReport, &out.DailyUsageReport
+		*out = make([]DailyUsageReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPUsageStatus.
+func (in *MCPUsageStatus) DeepCopy() *MCPUsageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPUsageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UsageOperatorStatus) DeepCopyInto(out *UsageOperatorStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ChargingTargetSplit != nil {
+		in, out := &in.ChargingTargetSplit, &out.ChargingTargetSplit
+		*out = make([]ChargingTargetShare, len(*in))
+		copy(*out, *in)
+	}
+	if in.ChargingTargetHistory != nil {
+		in, out := &in.ChargingTargetHistory, &out.ChargingTargetHistory
+		*out = make([]ChargingTargetAssignment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = make([]DailyUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastUsageCaptured.DeepCopyInto(&out.LastUsageCaptured)
+	in.MCPCreatedAt.DeepCopyInto(&out.MCPCreatedAt)
+	in.MCPDeletedAt.DeepCopyInto(&out.MCPDeletedAt)
+	if in.Lifecycle != nil {
+		in, out := &in.Lifecycle, &out.Lifecycle
+		*out = make([]LifecycleInterval, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UsageOperatorStatus.
+func (in *UsageOperatorStatus) DeepCopy() *UsageOperatorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UsageOperatorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
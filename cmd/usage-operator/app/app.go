@@ -37,6 +37,9 @@ func NewUsageOperatorCommand(ctx context.Context) *cobra.Command {
 	cmd.AddCommand(NewInitCommand(so))
 	cmd.AddCommand(NewRunCommand(so))
 	cmd.AddCommand(NewUninstallCommand(so))
+	cmd.AddCommand(NewMigrateStorageCommand(so))
+	cmd.AddCommand(NewReportCommand(so))
+	cmd.AddCommand(NewExportCommand(so))
 
 	return cmd
 }
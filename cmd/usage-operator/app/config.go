@@ -0,0 +1,120 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openmcp-project/usage-operator/internal/config"
+	"github.com/openmcp-project/usage-operator/internal/helper"
+	"github.com/openmcp-project/usage-operator/internal/usage"
+)
+
+// ConfigOptions are the flags of the usage-operator config. They are shared by all commands, which track the usage
+// of MCPs, so they capture it the same way.
+type ConfigOptions struct {
+	ConfigPath               string        `json:"config"`
+	BillingTimezone          string        `json:"billing-timezone"`
+	BillablePhases           []string      `json:"billable-phases"`
+	UsageGranularity         time.Duration `json:"usage-granularity"`
+	UsageKeyBy               string        `json:"usage-key-by"`
+	GCRetention              time.Duration `json:"gc-retention"`
+	GCDryRun                 bool          `json:"gc-dry-run"`
+	GCRequireAcknowledgement bool          `json:"gc-require-acknowledgement"`
+	GCMaxAge                 time.Duration `json:"gc-max-age"`
+
+	CloudEventsSink      string `json:"cloud-events-sink"`
+	CloudEventsDirectory string `json:"cloud-events-directory"`
+}
+
+func (o *ConfigOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.ConfigPath, "config", "", "Path to the usage-operator config file. Values set via flags take precedence over the config file.")
+	cmd.Flags().StringVar(&o.BillingTimezone, "billing-timezone", "", "IANA timezone which determines the day boundaries of the daily usage, e.g. Europe/Berlin. Can be overridden per project or workspace with the openmcp.cloud.sap/billing-timezone label. Defaults to UTC.")
+	cmd.Flags().StringSliceVar(&o.BillablePhases, "billable-phases", nil, "Statuses of an MCP, in which its usage is billable. Time in other statuses is captured as non-billable usage. Defaults to Ready.")
+	cmd.Flags().DurationVar(&o.UsageGranularity, "usage-granularity", 0, "Precision with which the usage is captured. Must be a multiple of 1s. Defaults to 1s.")
+	cmd.Flags().StringVar(&o.UsageKeyBy, "usage-key-by", "", "How new MCPUsages are named, either 'name' (project, workspace and mcp name) or 'uid' (uid of the mcp). Existing MCPUsages keep their name. Defaults to name.")
+	cmd.Flags().DurationVar(&o.GCRetention, "gc-retention", 0, "Duration for which daily usage entries are kept. Can be overridden per MCPUsage with the usage.openmcp.cloud/retention annotation. Defaults to 768h (32 days).")
+	cmd.Flags().BoolVar(&o.GCDryRun, "gc-dry-run", false, "If set, the garbage collection only logs which daily usage entries would be pruned.")
+	cmd.Flags().BoolVar(&o.GCRequireAcknowledgement, "gc-require-acknowledgement", false, "If set, the garbage collection keeps daily usage entries, until the metering operator reported them with their current content.")
+	cmd.Flags().DurationVar(&o.GCMaxAge, "gc-max-age", 0, "Age after which daily usage entries are pruned, even if the metering operator didn't acknowledge them. Only used with --gc-require-acknowledgement. Defaults to keeping them forever.")
+	cmd.Flags().StringVar(&o.CloudEventsSink, "cloud-events-sink", "", "HTTP(S) url, to which CloudEvents about the usage are posted. If empty, no events are published.")
+	cmd.Flags().StringVar(&o.CloudEventsDirectory, "cloud-events-directory", "", "Directory, in which undelivered CloudEvents are kept. Should be on a persistent volume. Defaults to /var/lib/usage-operator/cloudevents.")
+}
+
+// Load reads the config file, if any, and overrides its values with the flags, which are set.
+func (o *ConfigOptions) Load() (*config.Config, error) {
+	cfg := config.New()
+	if o.ConfigPath != "" {
+		var err error
+		cfg, err = config.LoadFromFile(o.ConfigPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load config: %w", err)
+		}
+	}
+	if o.BillingTimezone != "" {
+		cfg.Billing.Timezone = o.BillingTimezone
+	}
+	if o.BillablePhases != nil {
+		cfg.Billing.BillablePhases = o.BillablePhases
+	}
+	if o.UsageGranularity != 0 {
+		cfg.Usage.Granularity.Duration = o.UsageGranularity
+	}
+	if o.UsageKeyBy != "" {
+		cfg.Usage.KeyBy = o.UsageKeyBy
+	}
+	if o.GCRetention != 0 {
+		cfg.GarbageCollection.Retention.Duration = o.GCRetention
+	}
+	if o.GCDryRun {
+		cfg.GarbageCollection.DryRun = true
+	}
+	if o.GCRequireAcknowledgement {
+		cfg.GarbageCollection.RequireAcknowledgement = true
+	}
+	if o.GCMaxAge != 0 {
+		cfg.GarbageCollection.MaxAge.Duration = o.GCMaxAge
+	}
+	if o.CloudEventsSink != "" {
+		cfg.CloudEvents.Sink = o.CloudEventsSink
+	}
+	if o.CloudEventsDirectory != "" {
+		cfg.CloudEvents.Directory = o.CloudEventsDirectory
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return cfg, nil
+}
+
+// newUsageTracker returns a usage tracker, which captures the usage as configured. The billing location of the config
+// is returned as well.
+func newUsageTracker(c client.Client, cfg *config.Config) (*usage.UsageTracker, *time.Location, error) {
+	billingLocation, err := time.LoadLocation(cfg.Billing.Timezone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to load billing timezone: %w", err)
+	}
+
+	chargingTargetResolver, err := helper.NewChargingTargetResolver(cfg.ChargingTarget)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create charging target resolver: %w", err)
+	}
+
+	usageTracker, err := usage.NewUsageTracker(c)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create usage tracker: %w", err)
+	}
+	usageTracker.
+		WithBillingLocation(billingLocation).
+		WithBillablePhases(cfg.Billing.BillablePhases).
+		WithGranularity(cfg.Usage.Granularity.Duration).
+		WithKeyByUID(cfg.Usage.KeyBy == config.KeyByUID).
+		WithChargingTargetResolver(chargingTargetResolver).
+		WithRetention(cfg.GarbageCollection.Retention.Duration).
+		WithGarbageCollectionDryRun(cfg.GarbageCollection.DryRun).
+		WithRequireAcknowledgement(cfg.GarbageCollection.RequireAcknowledgement).
+		WithMaxAge(cfg.GarbageCollection.MaxAge.Duration)
+	return usageTracker, billingLocation, nil
+}
@@ -0,0 +1,159 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/yaml"
+
+	usagev2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+	"github.com/openmcp-project/usage-operator/internal/config"
+	"github.com/openmcp-project/usage-operator/internal/export"
+	"github.com/openmcp-project/usage-operator/internal/helper"
+)
+
+func NewExportCommand(so *SharedOptions) *cobra.Command {
+	opts := &ExportOptions{
+		SharedOptions: so,
+	}
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Exports the usage of the MCPs per day for file-based billing",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := opts.Complete(cmd.Context()); err != nil {
+				panic(fmt.Errorf("error completing options: %w", err))
+			}
+			// the options are only printed in dry run mode, as the export can be written to stdout
+			if opts.DryRun {
+				opts.PrintCompletedOptions(cmd)
+				cmd.Println("=== END OF DRY RUN ===")
+				return
+			}
+			if err := opts.Run(cmd.Context(), cmd); err != nil {
+				panic(err)
+			}
+		},
+	}
+	opts.AddFlags(cmd)
+
+	return cmd
+}
+
+type ExportOptions struct {
+	*SharedOptions
+
+	Format          string
+	Output          string
+	CursorFile      string
+	BillingTimezone string
+
+	// fields filled in Complete()
+	billingLocation *time.Location
+}
+
+func (o *ExportOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Format, "format", export.FormatCSV, fmt.Sprintf("File format of the export, one of %s.", strings.Join(export.Formats, ", ")))
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "File the export is written to. Defaults to stdout.")
+	cmd.Flags().StringVar(&o.CursorFile, "cursor-file", "", "File which remembers the exported days. If set, only days which were not exported before are written and the file is updated afterwards.")
+	cmd.Flags().StringVar(&o.BillingTimezone, "billing-timezone", config.DefaultBillingTimezone, "Timezone of the days of MCPUsages without a billing timezone of their own. Should match the billing timezone of the usage-operator.")
+}
+
+func (o *ExportOptions) Complete(ctx context.Context) error {
+	if err := o.SharedOptions.Complete(); err != nil {
+		return err
+	}
+
+	if !slices.Contains(export.Formats, o.Format) {
+		return fmt.Errorf("invalid --format %q, must be one of %s", o.Format, strings.Join(export.Formats, ", "))
+	}
+
+	loc, err := time.LoadLocation(o.BillingTimezone)
+	if err != nil {
+		return fmt.Errorf("invalid billing timezone %q: %w", o.BillingTimezone, err)
+	}
+	o.billingLocation = loc
+
+	return nil
+}
+
+func (o *ExportOptions) Run(ctx context.Context, cmd *cobra.Command) error {
+	log := o.Log.WithName("main")
+
+	cursor := export.Cursor{}
+	if o.CursorFile != "" {
+		var err error
+		if cursor, err = export.LoadCursor(o.CursorFile); err != nil {
+			return err
+		}
+	}
+
+	cluster, err := helper.GetOnboardingCluster(ctx, log, o.PlatformCluster.Client())
+	if err != nil {
+		return fmt.Errorf("error when getting onboarding cluster: %w", err)
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(usagev2.AddToScheme(scheme))
+	if err := cluster.InitializeClient(scheme); err != nil {
+		return fmt.Errorf("error initializing client: %w", err)
+	}
+
+	var mcpUsages usagev2.MCPUsageList
+	if err := cluster.Client().List(ctx, &mcpUsages); err != nil {
+		return fmt.Errorf("error listing MCPUsages: %w", err)
+	}
+
+	records, next := export.Records(mcpUsages.Items, cursor, o.billingLocation, time.Now())
+	log.Info("exporting usage", "records", len(records), "format", o.Format)
+
+	var data bytes.Buffer
+	if err := export.Write(&data, records, o.Format); err != nil {
+		return fmt.Errorf("error writing export: %w", err)
+	}
+	if o.Output == "" {
+		if _, err := cmd.OutOrStdout().Write(data.Bytes()); err != nil {
+			return fmt.Errorf("error writing export: %w", err)
+		}
+	} else if err := os.WriteFile(o.Output, data.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("error writing export: %w", err)
+	}
+
+	// the cursor is only moved after the export was written, so no day gets lost
+	if o.CursorFile != "" {
+		if err := next.Save(o.CursorFile); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *ExportOptions) PrintCompleted(cmd *cobra.Command) {
+	rawData := map[string]any{
+		"format":           o.Format,
+		"output":           o.Output,
+		"cursor-file":      o.CursorFile,
+		"billing-timezone": o.billingLocation.String(),
+	}
+	data, err := yaml.Marshal(rawData)
+	if err != nil {
+		cmd.Println(fmt.Errorf("error marshalling completed options: %w", err).Error())
+		return
+	}
+	cmd.Print(string(data))
+}
+
+func (o *ExportOptions) PrintCompletedOptions(cmd *cobra.Command) {
+	cmd.Println("########## COMPLETED OPTIONS START ##########")
+	o.SharedOptions.PrintCompleted(cmd)
+	o.PrintCompleted(cmd)
+	cmd.Println("########## COMPLETED OPTIONS END ##########")
+}
@@ -3,12 +3,17 @@ package app
 import (
 	"context"
 	"fmt"
+	"os"
 
 	crdutil "github.com/openmcp-project/controller-utils/pkg/crds"
 	apiconst "github.com/openmcp-project/openmcp-operator/api/constants"
 	"github.com/openmcp-project/openmcp-operator/api/install"
 	"github.com/spf13/cobra"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 
 	"github.com/openmcp-project/usage-operator/api/crds"
 	"github.com/openmcp-project/usage-operator/internal/helper"
@@ -42,15 +47,34 @@ func NewInitCommand(so *SharedOptions) *cobra.Command {
 
 type InitOptions struct {
 	*SharedOptions
+
+	ConversionWebhookURL    string
+	ConversionWebhookCAFile string
+	conversionWebhookCA     []byte
+
+	// existingMCPUsageCRD is the MCPUsage CRD, which is installed in the onboarding cluster, if any
+	existingMCPUsageCRD *apiextv1.CustomResourceDefinition
 }
 
 func (o *InitOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.ConversionWebhookURL, "conversion-webhook-url", "", "URL of the conversion webhook of the usage-operator, under which the onboarding cluster reaches it, e.g. https://usage-operator.example.com/convert. Without it, a webhook, which is already configured, is kept. Otherwise only the storage version of the MCPUsage is served, which is refused while MCPUsages are still stored as v1.")
+	cmd.Flags().StringVar(&o.ConversionWebhookCAFile, "conversion-webhook-ca-file", "", "Path to the PEM encoded CA bundle, which signed the certificate of the conversion webhook.")
 }
 
 func (o *InitOptions) Complete(ctx context.Context) error {
 	if err := o.SharedOptions.Complete(); err != nil {
 		return err
 	}
+	if o.ConversionWebhookCAFile != "" {
+		if o.ConversionWebhookURL == "" {
+			return fmt.Errorf("--conversion-webhook-ca-file requires --conversion-webhook-url")
+		}
+		ca, err := os.ReadFile(o.ConversionWebhookCAFile)
+		if err != nil {
+			return fmt.Errorf("error reading conversion webhook ca file: %w", err)
+		}
+		o.conversionWebhookCA = ca
+	}
 	return nil
 }
 
@@ -59,7 +83,7 @@ func (o *InitOptions) Run(ctx context.Context) error {
 	log.Info("Environment", "value", o.Environment)
 
 	// apply CRDs
-	crdManager := crdutil.NewCRDManager(apiconst.ClusterLabel, crds.CRDs)
+	crdManager := crdutil.NewCRDManager(apiconst.ClusterLabel, o.crds)
 
 	cluster, err := helper.GetOnboardingCluster(ctx, log, o.PlatformCluster.Client())
 	if err != nil {
@@ -70,6 +94,17 @@ func (o *InitOptions) Run(ctx context.Context) error {
 		return fmt.Errorf("error initializing client: %w", err)
 	}
 
+	// the conversion depends on the versions, in which the MCPUsages are stored
+	existing := &apiextv1.CustomResourceDefinition{}
+	err = cluster.Client().Get(ctx, client.ObjectKey{Name: crds.MCPUsageCRDName}, existing)
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return fmt.Errorf("error getting CRD %s: %w", crds.MCPUsageCRDName, err)
+	default:
+		o.existingMCPUsageCRD = existing
+	}
+
 	crdManager.AddCRDLabelToClusterMapping("onboarding", cluster)
 
 	if err := crdManager.CreateOrUpdateCRDs(ctx, &log); err != nil {
@@ -80,7 +115,34 @@ func (o *InitOptions) Run(ctx context.Context) error {
 	return nil
 }
 
-func (o *InitOptions) PrintCompleted(cmd *cobra.Command) {}
+// crds returns the CRDs of the usage-operator with the configured conversion webhook.
+func (o *InitOptions) crds() ([]*apiextv1.CustomResourceDefinition, error) {
+	crdList, err := crds.CRDs()
+	if err != nil {
+		return nil, err
+	}
+	for _, crd := range crdList {
+		if crd.Name == crds.MCPUsageCRDName {
+			if err := crds.ConfigureConversion(crd, o.existingMCPUsageCRD, o.ConversionWebhookURL, o.conversionWebhookCA); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return crdList, nil
+}
+
+func (o *InitOptions) PrintCompleted(cmd *cobra.Command) {
+	rawData := map[string]any{
+		"conversion-webhook-url":     o.ConversionWebhookURL,
+		"conversion-webhook-ca-file": o.ConversionWebhookCAFile,
+	}
+	data, err := yaml.Marshal(rawData)
+	if err != nil {
+		cmd.Println(fmt.Errorf("error marshalling completed options: %w", err).Error())
+		return
+	}
+	cmd.Print(string(data))
+}
 
 func (o *InitOptions) PrintCompletedOptions(cmd *cobra.Command) {
 	cmd.Println("########## COMPLETED OPTIONS START ##########")
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openmcp-project/openmcp-operator/api/install"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+
+	usagev2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+	"github.com/openmcp-project/usage-operator/internal/helper"
+)
+
+func NewMigrateStorageCommand(so *SharedOptions) *cobra.Command {
+	opts := &MigrateStorageOptions{
+		SharedOptions: so,
+	}
+	cmd := &cobra.Command{
+		Use:   "migrate-storage",
+		Short: "Stores all MCPUsages in the storage version of their CRD",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := opts.Complete(cmd.Context()); err != nil {
+				panic(fmt.Errorf("error completing options: %w", err))
+			}
+			opts.PrintCompletedOptions(cmd)
+			if opts.DryRun {
+				cmd.Println("=== END OF DRY RUN ===")
+				return
+			}
+			if err := opts.Run(cmd.Context()); err != nil {
+				panic(err)
+			}
+		},
+	}
+
+	return cmd
+}
+
+type MigrateStorageOptions struct {
+	*SharedOptions
+}
+
+func (o *MigrateStorageOptions) Complete(ctx context.Context) error {
+	return o.SharedOptions.Complete()
+}
+
+func (o *MigrateStorageOptions) Run(ctx context.Context) error {
+	log := o.Log.WithName("main")
+
+	cluster, err := helper.GetOnboardingCluster(ctx, log, o.PlatformCluster.Client())
+	if err != nil {
+		return fmt.Errorf("error when getting onboarding cluster: %w", err)
+	}
+
+	scheme := install.InstallCRDAPIs(runtime.NewScheme())
+	utilruntime.Must(usagev2.AddToScheme(scheme))
+	if err := cluster.InitializeClient(scheme); err != nil {
+		return fmt.Errorf("error initializing client: %w", err)
+	}
+
+	if err := helper.MigrateStorageVersion(ctx, log, cluster.Client()); err != nil {
+		return fmt.Errorf("error migrating the storage version: %w", err)
+	}
+
+	log.Info("Finished migrate-storage command")
+	return nil
+}
+
+func (o *MigrateStorageOptions) PrintCompletedOptions(cmd *cobra.Command) {
+	cmd.Println("########## COMPLETED OPTIONS START ##########")
+	o.SharedOptions.PrintCompleted(cmd)
+	cmd.Println("########## COMPLETED OPTIONS END ##########")
+}
@@ -0,0 +1,162 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/yaml"
+
+	usagev2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+	"github.com/openmcp-project/usage-operator/internal/config"
+	"github.com/openmcp-project/usage-operator/internal/helper"
+	"github.com/openmcp-project/usage-operator/internal/report"
+)
+
+func NewReportCommand(so *SharedOptions) *cobra.Command {
+	opts := &ReportOptions{
+		SharedOptions: so,
+	}
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Prints the usage of the MCPs aggregated over a date range",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := opts.Complete(cmd.Context()); err != nil {
+				panic(fmt.Errorf("error completing options: %w", err))
+			}
+			// the options are only printed in dry run mode, so the report can be parsed
+			if opts.DryRun {
+				opts.PrintCompletedOptions(cmd)
+				cmd.Println("=== END OF DRY RUN ===")
+				return
+			}
+			if err := opts.Run(cmd.Context(), cmd); err != nil {
+				panic(err)
+			}
+		},
+	}
+	opts.AddFlags(cmd)
+
+	return cmd
+}
+
+type ReportOptions struct {
+	*SharedOptions
+
+	From            string
+	To              string
+	GroupBy         string
+	Output          string
+	BillingTimezone string
+
+	// fields filled in Complete()
+	from            time.Time
+	to              time.Time
+	billingLocation *time.Location
+}
+
+func (o *ReportOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.From, "from", "", "First day of the report, formatted as YYYY-MM-DD. Defaults to the first day of the current month.")
+	cmd.Flags().StringVar(&o.To, "to", "", "Last day of the report, formatted as YYYY-MM-DD. Defaults to today.")
+	cmd.Flags().StringVar(&o.GroupBy, "group-by", report.GroupByProject, fmt.Sprintf("Grouping of the usage totals, one of %s.", strings.Join(report.GroupBys, ", ")))
+	cmd.Flags().StringVarP(&o.Output, "output", "o", report.FormatTable, fmt.Sprintf("Output format, one of %s.", strings.Join(report.Formats, ", ")))
+	cmd.Flags().StringVar(&o.BillingTimezone, "billing-timezone", config.DefaultBillingTimezone, "Timezone of the days of MCPUsages without a billing timezone of their own. Should match the billing timezone of the usage-operator.")
+}
+
+func (o *ReportOptions) Complete(ctx context.Context) error {
+	if err := o.SharedOptions.Complete(); err != nil {
+		return err
+	}
+
+	loc, err := time.LoadLocation(o.BillingTimezone)
+	if err != nil {
+		return fmt.Errorf("invalid billing timezone %q: %w", o.BillingTimezone, err)
+	}
+	o.billingLocation = loc
+
+	today := time.Now().In(loc)
+	o.from = time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, loc)
+	if o.From != "" {
+		if o.from, err = time.ParseInLocation(report.DateFormat, o.From, loc); err != nil {
+			return fmt.Errorf("invalid --from date: %w", err)
+		}
+	}
+	o.to = today
+	if o.To != "" {
+		if o.to, err = time.ParseInLocation(report.DateFormat, o.To, loc); err != nil {
+			return fmt.Errorf("invalid --to date: %w", err)
+		}
+	}
+	if o.to.Before(o.from) {
+		return fmt.Errorf("--to %s is before --from %s", o.to.Format(report.DateFormat), o.from.Format(report.DateFormat))
+	}
+
+	if !slices.Contains(report.GroupBys, o.GroupBy) {
+		return fmt.Errorf("invalid --group-by %q, must be one of %s", o.GroupBy, strings.Join(report.GroupBys, ", "))
+	}
+	if !slices.Contains(report.Formats, o.Output) {
+		return fmt.Errorf("invalid --output %q, must be one of %s", o.Output, strings.Join(report.Formats, ", "))
+	}
+
+	return nil
+}
+
+func (o *ReportOptions) Run(ctx context.Context, cmd *cobra.Command) error {
+	log := o.Log.WithName("main")
+
+	cluster, err := helper.GetOnboardingCluster(ctx, log, o.PlatformCluster.Client())
+	if err != nil {
+		return fmt.Errorf("error when getting onboarding cluster: %w", err)
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(usagev2.AddToScheme(scheme))
+	if err := cluster.InitializeClient(scheme); err != nil {
+		return fmt.Errorf("error initializing client: %w", err)
+	}
+
+	var mcpUsages usagev2.MCPUsageList
+	if err := cluster.Client().List(ctx, &mcpUsages); err != nil {
+		return fmt.Errorf("error listing MCPUsages: %w", err)
+	}
+
+	usageReport, err := report.Aggregate(mcpUsages.Items, report.Options{
+		From:            o.from,
+		To:              o.to,
+		GroupBy:         o.GroupBy,
+		BillingLocation: o.billingLocation,
+	})
+	if err != nil {
+		return fmt.Errorf("error aggregating usage: %w", err)
+	}
+
+	return report.Write(cmd.OutOrStdout(), usageReport, o.Output)
+}
+
+func (o *ReportOptions) PrintCompleted(cmd *cobra.Command) {
+	rawData := map[string]any{
+		"from":             o.from.Format(report.DateFormat),
+		"to":               o.to.Format(report.DateFormat),
+		"group-by":         o.GroupBy,
+		"output":           o.Output,
+		"billing-timezone": o.billingLocation.String(),
+	}
+	data, err := yaml.Marshal(rawData)
+	if err != nil {
+		cmd.Println(fmt.Errorf("error marshalling completed options: %w", err).Error())
+		return
+	}
+	cmd.Print(string(data))
+}
+
+func (o *ReportOptions) PrintCompletedOptions(cmd *cobra.Command) {
+	cmd.Println("########## COMPLETED OPTIONS START ##########")
+	o.SharedOptions.PrintCompleted(cmd)
+	o.PrintCompleted(cmd)
+	cmd.Println("########## COMPLETED OPTIONS END ##########")
+}
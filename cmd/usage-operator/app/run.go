@@ -23,11 +23,14 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 
 	usagev1 "github.com/openmcp-project/usage-operator/api/usage/v1"
+	usagev2 "github.com/openmcp-project/usage-operator/api/usage/v2"
 
+	"github.com/openmcp-project/usage-operator/internal/cloudevents"
+	"github.com/openmcp-project/usage-operator/internal/config"
 	"github.com/openmcp-project/usage-operator/internal/controller"
 	"github.com/openmcp-project/usage-operator/internal/helper"
+	"github.com/openmcp-project/usage-operator/internal/query"
 	"github.com/openmcp-project/usage-operator/internal/runnable"
-	"github.com/openmcp-project/usage-operator/internal/usage"
 )
 
 var setupLog logging.Logger
@@ -42,6 +45,7 @@ func init() {
 	utilruntime.Must(corev1alpha1.AddToScheme(scheme))
 	utilruntime.Must(pwcorev1alpha1.AddToScheme(scheme))
 	utilruntime.Must(usagev1.AddToScheme(scheme))
+	utilruntime.Must(usagev2.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -85,7 +89,12 @@ func (o *RunOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.MetricsCertPath, "metrics-cert-path", "", "The directory that contains the metrics server certificate.")
 	cmd.Flags().StringVar(&o.MetricsCertName, "metrics-cert-name", "tls.crt", "The name of the metrics server certificate file.")
 	cmd.Flags().StringVar(&o.MetricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
+	cmd.Flags().BoolVar(&o.EnableConversionWebhook, "enable-conversion-webhook", false, "If set, the webhook server serves the conversion webhook of the MCPUsage. It must be configured with the init command.")
 	cmd.Flags().BoolVar(&o.EnableHTTP2, "enable-http2", false, "If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	cmd.Flags().BoolVar(&o.EnableUsageAPI, "enable-usage-api", false, "If set, the metrics server serves the read-only usage API under /usage/. Requires --metrics-secure, so requests are authenticated and authorized like the metrics endpoint.")
+
+	// usage-operator flags
+	o.ConfigOptions.AddFlags(cmd)
 }
 
 type RawRunOptions struct {
@@ -102,6 +111,11 @@ type RawRunOptions struct {
 	PprofAddr            string `json:"pprof-bind-address"`
 	SecureMetrics        bool   `json:"metrics-secure"`
 	EnableHTTP2          bool   `json:"enable-http2"`
+
+	EnableConversionWebhook bool `json:"enable-conversion-webhook"`
+	EnableUsageAPI          bool `json:"enable-usage-api"`
+
+	ConfigOptions
 }
 
 type RunOptions struct {
@@ -114,6 +128,7 @@ type RunOptions struct {
 	MetricsServerOptions metricsserver.Options
 	MetricsCertWatcher   *certwatcher.CertWatcher
 	WebhookCertWatcher   *certwatcher.CertWatcher
+	Config               *config.Config
 }
 
 func (o *RunOptions) PrintRaw(cmd *cobra.Command) {
@@ -139,6 +154,13 @@ func (o *RunOptions) Complete(ctx context.Context) error {
 	setupLog = o.Log.WithName("setup")
 	ctrl.SetLogger(o.Log.Logr())
 
+	// usage-operator config
+	var err error
+	o.Config, err = o.ConfigOptions.Load()
+	if err != nil {
+		return err
+	}
+
 	// kubebuilder default stuff
 
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
@@ -193,6 +215,16 @@ func (o *RunOptions) Complete(ctx context.Context) error {
 		o.MetricsServerOptions.FilterProvider = filters.WithAuthenticationAndAuthorization
 	}
 
+	// the usage API exposes the usage of all MCPs, so it is never served without authn/authz
+	if o.EnableUsageAPI {
+		if o.MetricsAddr == "0" {
+			return fmt.Errorf("--enable-usage-api requires the metrics server, set --metrics-bind-address")
+		}
+		if !o.SecureMetrics {
+			return fmt.Errorf("--enable-usage-api requires --metrics-secure")
+		}
+	}
+
 	// If the certificate is not specified, controller-runtime will automatically
 	// generate self-signed certificates for the metrics server. While convenient for development and testing,
 	// this setup is not recommended for production.
@@ -222,7 +254,9 @@ func (o *RunOptions) Complete(ctx context.Context) error {
 }
 
 func (o *RunOptions) PrintCompleted(cmd *cobra.Command) {
-	rawData := map[string]any{}
+	rawData := map[string]any{
+		"config": o.Config,
+	}
 	data, err := yaml.Marshal(rawData)
 	if err != nil {
 		cmd.Println(fmt.Errorf("error marshalling completed options: %w", err).Error())
@@ -275,12 +309,26 @@ func (o *RunOptions) Run(ctx context.Context) error {
 		return fmt.Errorf("unable to create manager: %w", err)
 	}
 
-	usageTracker, err := usage.NewUsageTracker(mgr.GetClient())
+	usageTracker, billingLocation, err := newUsageTracker(mgr.GetClient(), o.Config)
 	if err != nil {
-		return fmt.Errorf("unable to create usage tracker: %w", err)
+		return err
+	}
+	usageTracker.WithEventRecorder(mgr.GetEventRecorder("usage-operator"))
+
+	runnable := runnable.NewUsageRunnable(mgr.GetClient(), usageTracker, mgr.GetEventRecorder("usage-operator"))
+
+	if o.Config.CloudEvents.Sink != "" {
+		publisher, err := cloudevents.NewPublisher(o.Config.CloudEvents.Sink, o.Config.CloudEvents.Source, filepath.Join(o.Config.CloudEvents.Directory, "outbox"))
+		if err != nil {
+			return fmt.Errorf("unable to create cloud events publisher: %w", err)
+		}
+		if err := mgr.Add(publisher); err != nil {
+			return fmt.Errorf("unable to add cloud events publisher: %w", err)
+		}
+		usageTracker.WithPublisher(publisher)
+		runnable.WithPublisher(publisher, filepath.Join(o.Config.CloudEvents.Directory, "closed-days.json"), billingLocation)
 	}
 
-	runnable := runnable.NewUsageRunnable(mgr.GetClient(), usageTracker)
 	if err := mgr.Add(&runnable); err != nil {
 		return fmt.Errorf("unable to add usage runnable: %w", err)
 	}
@@ -289,11 +337,25 @@ func (o *RunOptions) Run(ctx context.Context) error {
 		Client:       mgr.GetClient(),
 		Scheme:       mgr.GetScheme(),
 		UsageTracker: usageTracker,
+		Recorder:     mgr.GetEventRecorder("usage-operator"),
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to create controller ManagedControlPlane: %w", err)
 	}
+	if o.EnableConversionWebhook {
+		if err := ctrl.NewWebhookManagedBy(mgr, &usagev1.MCPUsage{}).Complete(); err != nil {
+			return fmt.Errorf("unable to create conversion webhook MCPUsage: %w", err)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
+	if o.EnableUsageAPI {
+		// the handler is served by the metrics server, so it uses the same authn/authz filter as the metrics endpoint
+		usageAPI := query.NewHandler(mgr.GetClient(), billingLocation, o.Log.WithName("usage-api").Logr())
+		if err := mgr.AddMetricsServerExtraHandler(query.PathPrefix, usageAPI); err != nil {
+			return fmt.Errorf("unable to add usage API to metrics server: %w", err)
+		}
+	}
+
 	if o.MetricsCertWatcher != nil {
 		setupLog.Info("Adding metrics certificate watcher to manager")
 		if err := mgr.Add(o.MetricsCertWatcher); err != nil {
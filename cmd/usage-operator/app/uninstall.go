@@ -6,11 +6,17 @@ import (
 	"fmt"
 
 	"github.com/openmcp-project/controller-utils/pkg/resources"
+	corev1alpha1 "github.com/openmcp-project/mcp-operator/api/core/v1alpha1"
 	"github.com/openmcp-project/openmcp-operator/api/install"
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/yaml"
 
 	"github.com/openmcp-project/usage-operator/api/crds"
+	usagev2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+	"github.com/openmcp-project/usage-operator/internal/config"
+	"github.com/openmcp-project/usage-operator/internal/controller"
 	"github.com/openmcp-project/usage-operator/internal/helper"
 )
 
@@ -42,16 +48,28 @@ func NewUninstallCommand(so *SharedOptions) *cobra.Command {
 
 type UninstallOptions struct {
 	*SharedOptions
+	// the final usage is captured with the same config as by the running usage-operator
+	ConfigOptions
+
+	KeepFinalizers bool
+
+	// fields filled in Complete()
+	Config *config.Config
 }
 
 func (o *UninstallOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.KeepFinalizers, "keep-finalizers", false, "Don't remove the usage finalizer from the ManagedControlPlanes. Without the usage-operator, ManagedControlPlanes with the finalizer can't be deleted.")
+	o.ConfigOptions.AddFlags(cmd)
 }
 
 func (o *UninstallOptions) Complete(ctx context.Context) error {
 	if err := o.SharedOptions.Complete(); err != nil {
 		return err
 	}
-	return nil
+
+	var err error
+	o.Config, err = o.ConfigOptions.Load()
+	return err
 }
 
 func (o *UninstallOptions) Run(ctx context.Context) error {
@@ -67,10 +85,25 @@ func (o *UninstallOptions) Run(ctx context.Context) error {
 		return fmt.Errorf("error when getting onboarding cluster: %w", err)
 	}
 
-	if err := cluster.InitializeClient(install.InstallCRDAPIs(runtime.NewScheme())); err != nil {
+	scheme := install.InstallCRDAPIs(runtime.NewScheme())
+	utilruntime.Must(corev1alpha1.AddToScheme(scheme))
+	utilruntime.Must(usagev2.AddToScheme(scheme))
+	if err := cluster.InitializeClient(scheme); err != nil {
 		return fmt.Errorf("error initializing client: %w", err)
 	}
 
+	// the finalizers have to be removed before the crds, so the deletion of mcps can still be captured
+	if !o.KeepFinalizers {
+		log.Info("removing usage finalizer from ManagedControlPlanes")
+		usageTracker, _, err := newUsageTracker(cluster.Client(), o.Config)
+		if err != nil {
+			return fmt.Errorf("error creating usage tracker: %w", err)
+		}
+		if err := controller.RemoveFinalizers(ctx, cluster.Client(), usageTracker); err != nil {
+			return fmt.Errorf("error removing finalizers: %w", err)
+		}
+	}
+
 	var errs error
 	for _, crd := range crdlist {
 		log.Info("uninstalling CRD", "name", crd.Name)
@@ -84,7 +117,18 @@ func (o *UninstallOptions) Run(ctx context.Context) error {
 	return errs
 }
 
-func (o *UninstallOptions) PrintCompleted(cmd *cobra.Command) {}
+func (o *UninstallOptions) PrintCompleted(cmd *cobra.Command) {
+	rawData := map[string]any{
+		"keep-finalizers": o.KeepFinalizers,
+		"config":          o.Config,
+	}
+	data, err := yaml.Marshal(rawData)
+	if err != nil {
+		cmd.Println(fmt.Errorf("error marshalling completed options: %w", err).Error())
+		return
+	}
+	cmd.Print(string(data))
+}
 
 func (o *UninstallOptions) PrintCompletedOptions(cmd *cobra.Command) {
 	cmd.Println("########## COMPLETED OPTIONS START ##########")
@@ -0,0 +1,110 @@
+// Package cloudevents publishes events about the usage of MCPs as CloudEvents to an HTTP sink. The events are
+// persisted in an outbox until the sink accepted them, so they are delivered at least once.
+package cloudevents
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SpecVersion is the version of the CloudEvents specification, which the events conform to.
+const SpecVersion = "1.0"
+
+// ContentType is the content type of an event in the structured content mode.
+const ContentType = "application/cloudevents+json; charset=UTF-8"
+
+const (
+	// TypeMCPCreated is published, when the usage of a new MCP, or a new incarnation of an MCP, is tracked.
+	TypeMCPCreated = "cloud.openmcp.usage.mcp.created"
+	// TypeMCPDeleted is published, when the deletion of an MCP was captured.
+	TypeMCPDeleted = "cloud.openmcp.usage.mcp.deleted"
+	// TypeChargingTargetChanged is published, when the charging target of an MCP was set or changed.
+	TypeChargingTargetChanged = "cloud.openmcp.usage.charging_target.changed"
+	// TypeDayClosed is published, when the usage of an MCP on a day is final.
+	TypeDayClosed = "cloud.openmcp.usage.day.closed"
+)
+
+// Event is a CloudEvent in the structured JSON format.
+type Event struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// NewEvent returns an event with the given data. The id is derived from the content of the event, so an event, which
+// is published again with the same content, has the same id and consumers can drop it as duplicate.
+func NewEvent(source, eventType, subject string, at time.Time, data any) (Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("error marshalling data of %s event: %w", eventType, err)
+	}
+
+	event := Event{
+		SpecVersion:     SpecVersion,
+		Source:          source,
+		Type:            eventType,
+		Subject:         subject,
+		Time:            at.UTC(),
+		DataContentType: "application/json",
+		Data:            raw,
+	}
+	content, err := json.Marshal(event)
+	if err != nil {
+		return Event{}, fmt.Errorf("error marshalling %s event: %w", eventType, err)
+	}
+	event.ID = uuid.NewSHA1(uuid.NameSpaceURL, content).String()
+	return event, nil
+}
+
+// MCPData is the data of the events about the creation and deletion of an MCP.
+type MCPData struct {
+	Project   string `json:"project"`
+	Workspace string `json:"workspace"`
+	MCP       string `json:"mcp"`
+	// MCPUsage is the name of the MCPUsage, which distinguishes incarnations of an MCP with the same name.
+	MCPUsage       string     `json:"mcp_usage"`
+	MCPUID         string     `json:"mcp_uid,omitempty"`
+	ChargingTarget string     `json:"charging_target,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+}
+
+// ChargingTargetData is the data of the event about a changed charging target.
+type ChargingTargetData struct {
+	Project                string    `json:"project"`
+	Workspace              string    `json:"workspace"`
+	MCP                    string    `json:"mcp"`
+	MCPUsage               string    `json:"mcp_usage"`
+	ChargingTarget         string    `json:"charging_target"`
+	ChargingTargetType     string    `json:"charging_target_type,omitempty"`
+	PreviousChargingTarget string    `json:"previous_charging_target,omitempty"`
+	EffectiveFrom          time.Time `json:"effective_from"`
+}
+
+// DayData is the data of the event about a closed day.
+type DayData struct {
+	Date                  string                `json:"date"`
+	Project               string                `json:"project"`
+	Workspace             string                `json:"workspace"`
+	MCP                   string                `json:"mcp"`
+	MCPUsage              string                `json:"mcp_usage"`
+	UsageHours            float64               `json:"usage_hours"`
+	NonBillableUsageHours float64               `json:"non_billable_usage_hours"`
+	ChargingTargets       []ChargingTargetHours `json:"charging_targets"`
+}
+
+// ChargingTargetHours is the part of the usage of a day, which is charged to a charging target.
+type ChargingTargetHours struct {
+	ChargingTarget        string  `json:"charging_target"`
+	ChargingTargetType    string  `json:"charging_target_type,omitempty"`
+	UsageHours            float64 `json:"usage_hours"`
+	NonBillableUsageHours float64 `json:"non_billable_usage_hours"`
+}
@@ -0,0 +1,122 @@
+package cloudevents
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// outbox persists events as one file per event in a directory, until they are delivered. The files are named after
+// the time they were added, so they are delivered in order. Events, which the sink rejected, are kept in the
+// dead-letter subdirectory for inspection.
+type outbox struct {
+	dir string
+
+	mu sync.Mutex
+	// last is the sequence number of the last added event. It is the time in nanoseconds, but strictly increasing.
+	last int64
+}
+
+// outboxEntry is an event of the outbox and the file it is stored in.
+type outboxEntry struct {
+	file  string
+	event Event
+}
+
+// deadLetterDir is the subdirectory of the outbox, which keeps the events rejected by the sink.
+const deadLetterDir = "dead-letter"
+
+func newOutbox(dir string) (*outbox, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating outbox directory %s: %w", dir, err)
+	}
+	return &outbox{dir: dir}, nil
+}
+
+// add stores the events in the outbox. Each file is written atomically, so a crash never leaves a broken event
+// behind.
+func (o *outbox) add(events ...Event) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("error marshalling event %s: %w", event.ID, err)
+		}
+
+		o.last = max(o.last+1, time.Now().UnixNano())
+		name := fmt.Sprintf("%020d-%s.json", o.last, event.ID)
+		tmp, err := os.CreateTemp(o.dir, ".tmp-*")
+		if err != nil {
+			return fmt.Errorf("error creating event file: %w", err)
+		}
+		if _, err := tmp.Write(data); err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+			return fmt.Errorf("error writing event file: %w", err)
+		}
+		if err := tmp.Close(); err != nil {
+			_ = os.Remove(tmp.Name())
+			return fmt.Errorf("error writing event file: %w", err)
+		}
+		if err := os.Rename(tmp.Name(), filepath.Join(o.dir, name)); err != nil {
+			_ = os.Remove(tmp.Name())
+			return fmt.Errorf("error storing event file: %w", err)
+		}
+	}
+	return nil
+}
+
+// pending returns the events of the outbox, oldest first.
+func (o *outbox) pending() ([]outboxEntry, error) {
+	files, err := os.ReadDir(o.dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading outbox directory %s: %w", o.dir, err)
+	}
+
+	var entries []outboxEntry
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(o.dir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error reading event file %s: %w", file.Name(), err)
+		}
+		var event Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			return nil, fmt.Errorf("error parsing event file %s: %w", file.Name(), err)
+		}
+		entries = append(entries, outboxEntry{file: file.Name(), event: event})
+	}
+	slices.SortFunc(entries, func(a, b outboxEntry) int { return strings.Compare(a.file, b.file) })
+	return entries, nil
+}
+
+// remove deletes a delivered event from the outbox.
+func (o *outbox) remove(entry outboxEntry) error {
+	err := os.Remove(filepath.Join(o.dir, entry.file))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error removing event file %s: %w", entry.file, err)
+	}
+	return nil
+}
+
+// deadLetter moves an event, which can't be delivered, out of the outbox into the dead letters.
+func (o *outbox) deadLetter(entry outboxEntry) error {
+	dir := filepath.Join(o.dir, deadLetterDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("error creating dead-letter directory %s: %w", dir, err)
+	}
+	if err := os.Rename(filepath.Join(o.dir, entry.file), filepath.Join(dir, entry.file)); err != nil {
+		return fmt.Errorf("error moving event file %s to the dead letters: %w", entry.file, err)
+	}
+	return nil
+}
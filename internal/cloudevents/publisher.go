@@ -0,0 +1,185 @@
+package cloudevents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/openmcp-project/usage-operator/internal/metrics"
+)
+
+const (
+	// initialBackoff is the delay before the first retry of a failed delivery. It doubles with every failure.
+	initialBackoff = time.Second
+	// maxBackoff is the maximum delay between two retries.
+	maxBackoff = 5 * time.Minute
+	// pollInterval is the interval, in which the outbox is checked without being notified about new events.
+	pollInterval = time.Minute
+)
+
+// Publisher stores events in the outbox and delivers them to the sink. It is a runnable of the manager, which
+// delivers the events in the order they were published and retries failed deliveries with exponential backoff.
+type Publisher struct {
+	sink   string
+	source string
+	outbox *outbox
+	client *http.Client
+	// notify wakes up the delivery, when an event was published
+	notify chan struct{}
+
+	initialBackoff time.Duration
+}
+
+// NewPublisher returns a publisher, which posts events to the sink url. The events are kept in the given directory,
+// until the sink accepted them. The source is the source attribute of the events.
+func NewPublisher(sink, source, dir string) (*Publisher, error) {
+	o, err := newOutbox(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Publisher{
+		sink:           sink,
+		source:         source,
+		outbox:         o,
+		client:         &http.Client{Timeout: 30 * time.Second},
+		notify:         make(chan struct{}, 1),
+		initialBackoff: initialBackoff,
+	}, nil
+}
+
+// Publish stores an event with the given data in the outbox. It is delivered asynchronously.
+func (p *Publisher) Publish(eventType, subject string, at time.Time, data any) error {
+	event, err := NewEvent(p.source, eventType, subject, at, data)
+	if err != nil {
+		return err
+	}
+	if err := p.outbox.add(event); err != nil {
+		return err
+	}
+
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (p *Publisher) NeedLeaderElection() bool {
+	return true
+}
+
+// Start delivers the events of the outbox until the context is cancelled. Events of a previous run are delivered
+// first.
+func (p *Publisher) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("cloudevents")
+
+	backoff := time.Duration(0)
+	for {
+		if err := p.deliver(ctx, log); err != nil {
+			backoff = min(max(2*backoff, p.initialBackoff), maxBackoff)
+			log.Error(err, "error delivering events, retrying", "backoff", backoff)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(backoff):
+			}
+			continue
+		}
+
+		backoff = 0
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-p.notify:
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// deliver sends the pending events in order and removes every accepted event from the outbox. It stops at the first
+// event, which can't be delivered, so the order is kept. Events, which the sink rejects permanently, are moved to the
+// dead letters instead.
+func (p *Publisher) deliver(ctx context.Context, log logr.Logger) error {
+	entries, err := p.outbox.pending()
+	if err != nil {
+		return err
+	}
+	metrics.CloudEventsPending.Set(float64(len(entries)))
+
+	for i, entry := range entries {
+		err := p.send(ctx, entry.event)
+		var rejected *rejectedError
+		switch {
+		case errors.As(err, &rejected):
+			// the sink will never accept the event, so it would block all further events
+			metrics.CloudEventsDeliveryFailures.Inc()
+			log.Error(err, "sink rejected event, moving it to the dead letters", "id", entry.event.ID, "type", entry.event.Type, "subject", entry.event.Subject)
+			if err := p.outbox.deadLetter(entry); err != nil {
+				return err
+			}
+			metrics.CloudEventsDeadLettered.Inc()
+		case err != nil:
+			metrics.CloudEventsDeliveryFailures.Inc()
+			return fmt.Errorf("error delivering event %s: %w", entry.event.ID, err)
+		default:
+			metrics.CloudEventsDelivered.Inc()
+			log.V(1).Info("delivered event", "id", entry.event.ID, "type", entry.event.Type, "subject", entry.event.Subject)
+
+			// an event, which can't be removed, is delivered again, which is fine for at-least-once delivery
+			if err := p.outbox.remove(entry); err != nil {
+				return err
+			}
+		}
+		metrics.CloudEventsPending.Set(float64(len(entries) - i - 1))
+	}
+	return nil
+}
+
+// send posts the event in the structured content mode. Any 2xx status means, that the sink accepted the event.
+func (p *Publisher) send(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error marshalling event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.sink, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", ContentType)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+		return nil
+	case resp.StatusCode >= 400 && resp.StatusCode <= 499 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests:
+		return &rejectedError{status: resp.Status}
+	default:
+		return fmt.Errorf("sink responded with %s", resp.Status)
+	}
+}
+
+// rejectedError is returned, if the sink rejected an event with a client error. Sending it again won't change that,
+// unlike timeouts and rate limits.
+type rejectedError struct {
+	status string
+}
+
+func (e *rejectedError) Error() string {
+	return fmt.Sprintf("sink rejected the event with %s", e.status)
+}
@@ -0,0 +1,185 @@
+package cloudevents
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/openmcp-project/usage-operator/internal/metrics"
+)
+
+// receiver is a local sink, which records the received events. It rejects all events while failing is set, and the
+// events of the rejected type with a client error.
+type receiver struct {
+	mu       sync.Mutex
+	events   []Event
+	failing  atomic.Bool
+	rejected string
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	defer GinkgoRecover()
+	if r.failing.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	Expect(req.Header.Get("Content-Type")).Should(Equal(ContentType))
+	body, err := io.ReadAll(req.Body)
+	Expect(err).ShouldNot(HaveOccurred())
+	var event Event
+	Expect(json.Unmarshal(body, &event)).To(Succeed())
+	if event.Type == r.rejected {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (r *receiver) received() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil), r.events...)
+}
+
+var _ = Describe("Publisher", func() {
+	var (
+		sink *receiver
+		srv  *httptest.Server
+		dir  string
+	)
+	at := time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC)
+
+	BeforeEach(func() {
+		sink = &receiver{}
+		srv = httptest.NewServer(sink)
+		DeferCleanup(srv.Close)
+		dir = GinkgoT().TempDir()
+	})
+
+	It("should derive the id of an event from its content", func() {
+		a, err := NewEvent("/usage-operator", TypeDayClosed, "usage/2025-07-01", at, DayData{Date: "2025-07-01", UsageHours: 24})
+		Expect(err).ShouldNot(HaveOccurred())
+		b, err := NewEvent("/usage-operator", TypeDayClosed, "usage/2025-07-01", at, DayData{Date: "2025-07-01", UsageHours: 24})
+		Expect(err).ShouldNot(HaveOccurred())
+		c, err := NewEvent("/usage-operator", TypeDayClosed, "usage/2025-07-01", at, DayData{Date: "2025-07-01", UsageHours: 12})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(a.ID).ShouldNot(BeEmpty())
+		Expect(a.ID).Should(Equal(b.ID))
+		Expect(a.ID).ShouldNot(Equal(c.ID))
+		Expect(a.SpecVersion).Should(Equal(SpecVersion))
+	})
+
+	It("should deliver the events in order and empty the outbox", func() {
+		publisher, err := NewPublisher(srv.URL, "/usage-operator", dir)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(publisher.Publish(TypeMCPCreated, "usage", at, MCPData{MCP: "mcp", CreatedAt: at})).To(Succeed())
+		Expect(publisher.Publish(TypeMCPDeleted, "usage", at.Add(time.Hour), MCPData{MCP: "mcp", CreatedAt: at})).To(Succeed())
+		Expect(publisher.deliver(context.Background(), logr.Discard())).To(Succeed())
+
+		events := sink.received()
+		Expect(events).Should(HaveLen(2))
+		Expect(events[0].Type).Should(Equal(TypeMCPCreated))
+		Expect(events[0].Source).Should(Equal("/usage-operator"))
+		Expect(events[0].Subject).Should(Equal("usage"))
+		Expect(string(events[0].Data)).Should(ContainSubstring(`"mcp":"mcp"`))
+		Expect(events[1].Type).Should(Equal(TypeMCPDeleted))
+
+		pending, err := publisher.outbox.pending()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(pending).Should(BeEmpty())
+	})
+
+	It("should keep the events in the outbox, until the sink accepts them", func() {
+		sink.failing.Store(true)
+		publisher, err := NewPublisher(srv.URL, "/usage-operator", dir)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(publisher.Publish(TypeMCPCreated, "usage", at, MCPData{MCP: "mcp"})).To(Succeed())
+
+		Expect(publisher.deliver(context.Background(), logr.Discard())).ShouldNot(Succeed())
+		pending, err := publisher.outbox.pending()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(pending).Should(HaveLen(1))
+
+		// a new publisher, e.g. after a restart, delivers the events of the outbox
+		restarted, err := NewPublisher(srv.URL, "/usage-operator", dir)
+		Expect(err).ShouldNot(HaveOccurred())
+		sink.failing.Store(false)
+		Expect(restarted.deliver(context.Background(), logr.Discard())).To(Succeed())
+		Expect(sink.received()).Should(HaveLen(1))
+		Expect(sink.received()[0].ID).Should(Equal(pending[0].event.ID))
+	})
+
+	It("should move events, which the sink rejects, to the dead letters and continue", func() {
+		sink.rejected = TypeMCPCreated
+		publisher, err := NewPublisher(srv.URL, "/usage-operator", dir)
+		Expect(err).ShouldNot(HaveOccurred())
+		deadLettered := testutil.ToFloat64(metrics.CloudEventsDeadLettered)
+
+		Expect(publisher.Publish(TypeMCPCreated, "usage", at, MCPData{MCP: "mcp"})).To(Succeed())
+		Expect(publisher.Publish(TypeMCPDeleted, "usage", at.Add(time.Hour), MCPData{MCP: "mcp"})).To(Succeed())
+		Expect(publisher.deliver(context.Background(), logr.Discard())).To(Succeed())
+
+		Expect(sink.received()).Should(HaveLen(1))
+		Expect(sink.received()[0].Type).Should(Equal(TypeMCPDeleted))
+		pending, err := publisher.outbox.pending()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(pending).Should(BeEmpty())
+		files, err := os.ReadDir(filepath.Join(dir, deadLetterDir))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(files).Should(HaveLen(1))
+		Expect(testutil.ToFloat64(metrics.CloudEventsDeadLettered)).Should(Equal(deadLettered + 1))
+	})
+
+	It("should retry events, which are rejected because of a timeout or rate limit", func() {
+		for _, status := range []int{http.StatusRequestTimeout, http.StatusTooManyRequests} {
+			limited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(status) }))
+			publisher, err := NewPublisher(limited.URL, "/usage-operator", GinkgoT().TempDir())
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(publisher.Publish(TypeMCPCreated, "usage", at, MCPData{MCP: "mcp"})).To(Succeed())
+			Expect(publisher.deliver(context.Background(), logr.Discard())).ShouldNot(Succeed())
+			pending, err := publisher.outbox.pending()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(pending).Should(HaveLen(1))
+			limited.Close()
+		}
+	})
+
+	It("should retry failed deliveries with backoff", func() {
+		sink.failing.Store(true)
+		publisher, err := NewPublisher(srv.URL, "/usage-operator", dir)
+		Expect(err).ShouldNot(HaveOccurred())
+		publisher.initialBackoff = 10 * time.Millisecond
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- publisher.Start(ctx) }()
+
+		Expect(publisher.Publish(TypeMCPCreated, "usage", at, MCPData{MCP: "mcp"})).To(Succeed())
+		Consistently(sink.received, "50ms").Should(BeEmpty())
+
+		sink.failing.Store(false)
+		Eventually(sink.received).Should(HaveLen(1))
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+	})
+})
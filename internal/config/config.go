@@ -0,0 +1,270 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"time"
+	// embeds the timezone database, so billing timezones can be resolved without relying on the host
+	_ "time/tzdata"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultGranularity is the default precision of the captured usage. Timestamps of the MCPUsage resource are
+// stored with second precision, so the usage can't be more precise than that.
+const DefaultGranularity = time.Second
+
+// DefaultBillingTimezone is the default timezone which determines the day boundaries of the daily usage.
+const DefaultBillingTimezone = "UTC"
+
+// DefaultBillablePhases are the phases of an MCP, in which its usage is billable by default.
+var DefaultBillablePhases = []string{"Ready"}
+
+const (
+	// KeyByName names MCPUsages after project, workspace and mcp name, so all incarnations of an MCP share one MCPUsage.
+	KeyByName = "name"
+	// KeyByUID names MCPUsages after the uid of the MCP, so every incarnation of an MCP gets its own MCPUsage.
+	KeyByUID = "uid"
+)
+
+const (
+	// DefaultChargingTargetKey is the default label key of the charging target.
+	DefaultChargingTargetKey = "openmcp.cloud.sap/charging-target"
+	// DefaultChargingTargetTypeKey is the default label key of the charging target type.
+	DefaultChargingTargetTypeKey = "openmcp.cloud.sap/charging-target-type"
+	// DefaultChargingTargetLockKey is the default label key, which locks the charging target of a level.
+	DefaultChargingTargetLockKey = "openmcp.cloud.sap/charging-target-locked"
+)
+
+const (
+	// SourceLabels reads the charging target from the labels.
+	SourceLabels = "labels"
+	// SourceAnnotations reads the charging target from the annotations.
+	SourceAnnotations = "annotations"
+)
+
+const (
+	LevelProject   = "project"
+	LevelWorkspace = "workspace"
+	LevelMCP       = "mcp"
+)
+
+// DefaultChargingTargetPrecedence is the default order of the levels, from which the charging target is resolved.
+// Later levels override earlier ones.
+var DefaultChargingTargetPrecedence = []string{LevelProject, LevelWorkspace, LevelMCP}
+
+// DefaultRetention is the default duration for which DailyUsage entries are kept before they are garbage collected.
+const DefaultRetention = 32 * 24 * time.Hour
+
+const (
+	// DefaultCloudEventsSource is the default source attribute of the published CloudEvents.
+	DefaultCloudEventsSource = "/usage-operator"
+	// DefaultCloudEventsDirectory is the default directory, in which the state of the CloudEvents publisher is kept.
+	DefaultCloudEventsDirectory = "/var/lib/usage-operator/cloudevents"
+)
+
+// Config is the configuration of the usage-operator. It can be provided as a file to the run command.
+type Config struct {
+	Billing           BillingConfig           `json:"billing"`
+	ChargingTarget    ChargingTargetConfig    `json:"charging-target"`
+	Usage             UsageConfig             `json:"usage"`
+	GarbageCollection GarbageCollectionConfig `json:"garbage-collection"`
+	CloudEvents       CloudEventsConfig       `json:"cloud-events"`
+}
+
+type BillingConfig struct {
+	// Timezone is the IANA timezone which determines the day boundaries of the daily usage, e.g. Europe/Berlin.
+	// It can be overridden per project or workspace with the openmcp.cloud.sap/billing-timezone label.
+	Timezone string `json:"timezone,omitempty"`
+	// BillablePhases are the statuses of an MCP, in which its usage is billable, e.g. Ready.
+	// Time in other phases, like provisioning or deleting, is captured as non-billable usage.
+	BillablePhases []string `json:"billable-phases,omitempty"`
+}
+
+type ChargingTargetConfig struct {
+	// Key is the label or annotation key, which contains the charging target.
+	Key string `json:"key,omitempty"`
+	// TypeKey is the label or annotation key, which contains the type of the charging target.
+	TypeKey string `json:"type-key,omitempty"`
+	// LockKey is the label or annotation key, which locks the charging target of a level, if it is set to "true".
+	// Levels after a locked level can't override its charging target.
+	LockKey string `json:"lock-key,omitempty"`
+	// Sources are the metadata fields, in which the keys are looked up, either "labels" or "annotations".
+	// The first source, which contains a key, wins.
+	Sources []string `json:"sources,omitempty"`
+	// Precedence is the order of the levels "project", "workspace" and "mcp". Later levels override earlier ones.
+	// Levels which are not listed are ignored.
+	Precedence []string `json:"precedence,omitempty"`
+	// Validate checks every charging target against the ChargingTarget resources. Unknown, expired or mismatching
+	// charging targets are reported with the ChargingTargetValid condition and an event.
+	Validate bool `json:"validate,omitempty"`
+	// Default is the charging target, which is used if no valid charging target is found for an MCP. If empty, the
+	// resolved charging target is kept, or "missing" is used.
+	Default string `json:"default,omitempty"`
+	// Rules derive the charging target of MCPs without an explicit charging target. The first matching rule wins.
+	Rules []ChargingTargetRule `json:"rules,omitempty"`
+}
+
+// ChargingTargetRule derives a charging target with a CEL expression. The expression can access the variables
+// project, workspace and mcp, which contain the name, namespace, labels and annotations of the objects. It must
+// evaluate to a string. An empty string means, that the rule doesn't match.
+type ChargingTargetRule struct {
+	// Name identifies the rule in the status of the MCPUsage.
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+	// Type is the charging target type of the derived charging targets.
+	Type string `json:"type,omitempty"`
+}
+
+type UsageConfig struct {
+	// Granularity is the precision with which the usage is captured, e.g. 1s or 1m.
+	// It must be a multiple of one second.
+	Granularity metav1.Duration `json:"granularity,omitempty"`
+	// KeyBy determines how MCPUsages are named, either "name" or "uid". Existing MCPUsages keep their name.
+	KeyBy string `json:"key-by,omitempty"`
+}
+
+type GarbageCollectionConfig struct {
+	// Retention is the duration for which DailyUsage entries are kept.
+	// It can be overridden per MCPUsage with the usage.openmcp.cloud/retention annotation.
+	Retention metav1.Duration `json:"retention,omitempty"`
+	// DryRun only logs the entries which would be pruned, without removing them.
+	DryRun bool `json:"dry-run,omitempty"`
+	// RequireAcknowledgement keeps entries beyond the retention, until the metering operator reported them with their
+	// current content.
+	RequireAcknowledgement bool `json:"require-acknowledgement,omitempty"`
+	// MaxAge is the age, after which entries are pruned, even if they were not acknowledged. Zero keeps unacknowledged
+	// entries forever.
+	MaxAge metav1.Duration `json:"max-age,omitempty"`
+}
+
+type CloudEventsConfig struct {
+	// Sink is the http(s) url, to which the CloudEvents are posted. If empty, no events are published.
+	Sink string `json:"sink,omitempty"`
+	// Source is the source attribute of the events.
+	Source string `json:"source,omitempty"`
+	// Directory contains the outbox with the undelivered events and the cursor of the published days. It should be
+	// on a persistent volume, so the events survive restarts.
+	Directory string `json:"directory,omitempty"`
+}
+
+// New returns the configuration which is used if no config file is provided.
+func New() *Config {
+	cfg := &Config{}
+	cfg.SetDefaults()
+	return cfg
+}
+
+// LoadFromFile reads the configuration from the given yaml file. Missing values are defaulted.
+func LoadFromFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+	cfg.SetDefaults()
+
+	return cfg, nil
+}
+
+func (c *Config) SetDefaults() {
+	if c.Billing.Timezone == "" {
+		c.Billing.Timezone = DefaultBillingTimezone
+	}
+	if c.Billing.BillablePhases == nil {
+		c.Billing.BillablePhases = slices.Clone(DefaultBillablePhases)
+	}
+	if c.ChargingTarget.Key == "" {
+		c.ChargingTarget.Key = DefaultChargingTargetKey
+	}
+	if c.ChargingTarget.TypeKey == "" {
+		c.ChargingTarget.TypeKey = DefaultChargingTargetTypeKey
+	}
+	if c.ChargingTarget.LockKey == "" {
+		c.ChargingTarget.LockKey = DefaultChargingTargetLockKey
+	}
+	if c.ChargingTarget.Sources == nil {
+		c.ChargingTarget.Sources = []string{SourceLabels}
+	}
+	if c.ChargingTarget.Precedence == nil {
+		c.ChargingTarget.Precedence = slices.Clone(DefaultChargingTargetPrecedence)
+	}
+	if c.Usage.Granularity.Duration == 0 {
+		c.Usage.Granularity.Duration = DefaultGranularity
+	}
+	if c.Usage.KeyBy == "" {
+		c.Usage.KeyBy = KeyByName
+	}
+	if c.GarbageCollection.Retention.Duration == 0 {
+		c.GarbageCollection.Retention.Duration = DefaultRetention
+	}
+	if c.CloudEvents.Source == "" {
+		c.CloudEvents.Source = DefaultCloudEventsSource
+	}
+	if c.CloudEvents.Directory == "" {
+		c.CloudEvents.Directory = DefaultCloudEventsDirectory
+	}
+}
+
+func (c *Config) Validate() error {
+	var errs error
+	if _, err := time.LoadLocation(c.Billing.Timezone); err != nil {
+		errs = errors.Join(errs, fmt.Errorf("billing.timezone is invalid: %w", err))
+	}
+	if err := validateList("charging-target.sources", c.ChargingTarget.Sources, SourceLabels, SourceAnnotations); err != nil {
+		errs = errors.Join(errs, err)
+	}
+	if err := validateList("charging-target.precedence", c.ChargingTarget.Precedence, LevelProject, LevelWorkspace, LevelMCP); err != nil {
+		errs = errors.Join(errs, err)
+	}
+	for i, rule := range c.ChargingTarget.Rules {
+		if rule.Name == "" || rule.Expression == "" {
+			errs = errors.Join(errs, fmt.Errorf("charging-target.rules[%d] must have a name and an expression", i))
+		}
+		if slices.ContainsFunc(c.ChargingTarget.Rules[:i], func(r ChargingTargetRule) bool { return r.Name == rule.Name }) {
+			errs = errors.Join(errs, fmt.Errorf("charging-target.rules must not contain the rule %q twice", rule.Name))
+		}
+	}
+	if granularity := c.Usage.Granularity.Duration; granularity < time.Second || granularity%time.Second != 0 {
+		errs = errors.Join(errs, fmt.Errorf("usage.granularity must be a positive multiple of 1s, got %s", granularity))
+	}
+	if c.Usage.KeyBy != KeyByName && c.Usage.KeyBy != KeyByUID {
+		errs = errors.Join(errs, fmt.Errorf("usage.key-by must be %q or %q, got %q", KeyByName, KeyByUID, c.Usage.KeyBy))
+	}
+	if c.GarbageCollection.Retention.Duration < 0 {
+		errs = errors.Join(errs, fmt.Errorf("garbage-collection.retention must not be negative, got %s", c.GarbageCollection.Retention.Duration))
+	}
+	if maxAge := c.GarbageCollection.MaxAge.Duration; maxAge != 0 && maxAge < c.GarbageCollection.Retention.Duration {
+		errs = errors.Join(errs, fmt.Errorf("garbage-collection.max-age must not be shorter than the retention %s, got %s", c.GarbageCollection.Retention.Duration, maxAge))
+	}
+	if c.CloudEvents.Sink != "" {
+		if sink, err := url.Parse(c.CloudEvents.Sink); err != nil || (sink.Scheme != "http" && sink.Scheme != "https") || sink.Host == "" {
+			errs = errors.Join(errs, fmt.Errorf("cloud-events.sink must be an http or https url, got %q", c.CloudEvents.Sink))
+		}
+	}
+	return errs
+}
+
+// validateList checks that the list is not empty and only contains each of the allowed values once.
+func validateList(field string, values []string, allowed ...string) error {
+	if len(values) == 0 {
+		return fmt.Errorf("%s must not be empty", field)
+	}
+	for i, value := range values {
+		if !slices.Contains(allowed, value) {
+			return fmt.Errorf("%s must only contain %v, got %q", field, allowed, value)
+		}
+		if slices.Contains(values[:i], value) {
+			return fmt.Errorf("%s must not contain %q twice", field, value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func writeConfig(content string) string {
+	path := filepath.Join(GinkgoT().TempDir(), "config.yaml")
+	Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+	return path
+}
+
+var _ = Describe("Config", func() {
+	It("should default the retention", func() {
+		cfg := New()
+		Expect(cfg.GarbageCollection.Retention.Duration).Should(Equal(DefaultRetention))
+		Expect(cfg.GarbageCollection.DryRun).Should(BeFalse())
+		Expect(cfg.Usage.Granularity.Duration).Should(Equal(DefaultGranularity))
+		Expect(cfg.Billing.Timezone).Should(Equal(DefaultBillingTimezone))
+		Expect(cfg.Billing.BillablePhases).Should(ConsistOf("Ready"))
+		Expect(cfg.Usage.KeyBy).Should(Equal(KeyByName))
+		Expect(cfg.ChargingTarget.Key).Should(Equal(DefaultChargingTargetKey))
+		Expect(cfg.ChargingTarget.Sources).Should(Equal([]string{SourceLabels}))
+		Expect(cfg.ChargingTarget.Precedence).Should(Equal([]string{LevelProject, LevelWorkspace, LevelMCP}))
+		Expect(cfg.Validate()).To(Succeed())
+	})
+
+	It("should load the config from a file", func() {
+		path := writeConfig(`
+garbage-collection:
+  retention: 2208h
+  dry-run: true
+`)
+		cfg, err := LoadFromFile(path)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(cfg.GarbageCollection.Retention.Duration).Should(Equal(92 * 24 * time.Hour))
+		Expect(cfg.GarbageCollection.DryRun).Should(BeTrue())
+	})
+
+	It("should default missing values of a config file", func() {
+		cfg, err := LoadFromFile(writeConfig("garbage-collection: {}\n"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(cfg.GarbageCollection.Retention.Duration).Should(Equal(DefaultRetention))
+	})
+
+	It("should reject unknown fields", func() {
+		_, err := LoadFromFile(writeConfig("garbage-collection:\n  retension: 24h\n"))
+		Expect(err).Should(HaveOccurred())
+	})
+
+	It("should reject a negative retention", func() {
+		cfg, err := LoadFromFile(writeConfig("garbage-collection:\n  retention: -24h\n"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(cfg.Validate()).ShouldNot(Succeed())
+	})
+
+	It("should reject a max age, which is shorter than the retention", func() {
+		cfg, err := LoadFromFile(writeConfig("garbage-collection:\n  retention: 768h\n  max-age: 240h\n"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(cfg.Validate()).ShouldNot(Succeed())
+
+		cfg.GarbageCollection.MaxAge.Duration = 0
+		Expect(cfg.Validate()).Should(Succeed())
+	})
+
+	It("should reject a granularity which is not a multiple of a second", func() {
+		cfg, err := LoadFromFile(writeConfig("usage:\n  granularity: 1500ms\n"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(cfg.Validate()).ShouldNot(Succeed())
+	})
+
+	It("should reject an unknown billing timezone", func() {
+		cfg, err := LoadFromFile(writeConfig("billing:\n  timezone: Mars/Olympus_Mons\n"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(cfg.Validate()).ShouldNot(Succeed())
+	})
+	It("should reject an unknown key", func() {
+		cfg, err := LoadFromFile(writeConfig("usage:\n  key-by: uuid\n"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(cfg.Validate()).ShouldNot(Succeed())
+	})
+
+	It("should reject an unknown charging target level", func() {
+		cfg, err := LoadFromFile(writeConfig("charging-target:\n  precedence: [project, cluster]\n"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(cfg.Validate()).ShouldNot(Succeed())
+	})
+
+	It("should reject a charging target level listed twice", func() {
+		cfg, err := LoadFromFile(writeConfig("charging-target:\n  precedence: [project, mcp, project]\n"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(cfg.Validate()).ShouldNot(Succeed())
+	})
+
+	It("should reject a charging target rule without expression", func() {
+		cfg, err := LoadFromFile(writeConfig("charging-target:\n  rules:\n  - name: project-name\n"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(cfg.Validate()).ShouldNot(Succeed())
+	})
+
+	It("should reject a cloud events sink, which is not an http url", func() {
+		cfg, err := LoadFromFile(writeConfig("cloud-events:\n  sink: broker.local:8080\n"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(cfg.CloudEvents.Directory).Should(Equal(DefaultCloudEventsDirectory))
+		Expect(cfg.Validate()).ShouldNot(Succeed())
+
+		cfg.CloudEvents.Sink = "http://broker.local:8080/events"
+		Expect(cfg.Validate()).Should(Succeed())
+	})
+})
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	corev1alpha1 "github.com/openmcp-project/mcp-operator/api/core/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/openmcp-project/usage-operator/api"
+	"github.com/openmcp-project/usage-operator/internal/usage"
+)
+
+var namespaceRegex = regexp.MustCompile("project-(.+)--ws-(.+)")
+
+// parseNamespace returns the project and workspace of the given mcp namespace.
+func parseNamespace(namespace string) (string, string, error) {
+	matches := namespaceRegex.FindStringSubmatch(namespace)
+	if len(matches) != 3 {
+		return "", "", errors.New("namespace of mcp is invalid")
+	}
+	return matches[1], matches[2], nil
+}
+
+// releaseManagedControlPlane captures the deletion of a mcp, which is being deleted, and removes the usage finalizer
+// afterwards. The finalizer is kept, if the deletion couldn't be written to the MCPUsage.
+func releaseManagedControlPlane(ctx context.Context, c client.Client, usageTracker *usage.UsageTracker, project, workspace string, mcp *corev1alpha1.ManagedControlPlane) error {
+	if !controllerutil.ContainsFinalizer(mcp, api.UsageFinalizer) {
+		return nil
+	}
+
+	// a missing MCPUsage means the mcp was never tracked, so there is no deletion to capture
+	err := usageTracker.DeletionEventAt(ctx, project, workspace, mcp.Name, mcp.UID, mcp.GetDeletionTimestamp().Time)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("error when tracking deletion: %w", err)
+	}
+
+	patch := client.MergeFromWithOptions(mcp.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	controllerutil.RemoveFinalizer(mcp, api.UsageFinalizer)
+	if err := c.Patch(ctx, mcp, patch); err != nil {
+		return fmt.Errorf("error when removing finalizer: %w", err)
+	}
+	return nil
+}
+
+// RemoveFinalizers removes the usage finalizer from all mcps, so they can be deleted without the usage-operator.
+// The deletion of mcps, which are already being deleted, is captured before their finalizer is removed.
+// The usage-operator must not be running, otherwise it adds the finalizer again.
+func RemoveFinalizers(ctx context.Context, c client.Client, usageTracker *usage.UsageTracker) error {
+	var mcps corev1alpha1.ManagedControlPlaneList
+	if err := c.List(ctx, &mcps); err != nil {
+		return fmt.Errorf("error listing mcps: %w", err)
+	}
+
+	var errs error
+	for i := range mcps.Items {
+		mcp := &mcps.Items[i]
+		if !controllerutil.ContainsFinalizer(mcp, api.UsageFinalizer) {
+			continue
+		}
+
+		if mcp.GetDeletionTimestamp() != nil {
+			project, workspace, err := parseNamespace(mcp.Namespace)
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("mcp %s/%s: %w", mcp.Namespace, mcp.Name, err))
+				continue
+			}
+			if err := releaseManagedControlPlane(ctx, c, usageTracker, project, workspace, mcp); err != nil {
+				errs = errors.Join(errs, fmt.Errorf("mcp %s/%s: %w", mcp.Namespace, mcp.Name, err))
+			}
+			continue
+		}
+
+		patch := client.MergeFromWithOptions(mcp.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		controllerutil.RemoveFinalizer(mcp, api.UsageFinalizer)
+		if err := c.Patch(ctx, mcp, patch); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("error removing finalizer from mcp %s/%s: %w", mcp.Namespace, mcp.Name, err))
+		}
+	}
+	return errs
+}
@@ -18,15 +18,23 @@ package controller
 
 import (
 	"context"
-	"errors"
-	"regexp"
+	"time"
 
 	"github.com/go-logr/logr"
 	corev1alpha1 "github.com/openmcp-project/mcp-operator/api/core/v1alpha1"
+	pwcorev1alpha1 "github.com/openmcp-project/project-workspace-operator/api/core/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
+	"github.com/openmcp-project/usage-operator/api"
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
 	"github.com/openmcp-project/usage-operator/internal/usage"
 )
 
@@ -36,11 +44,16 @@ type ManagedControlPlaneReconciler struct {
 	Scheme *runtime.Scheme
 
 	UsageTracker *usage.UsageTracker
+	// Recorder records events about the usage tracking on the mcps. It is optional.
+	Recorder events.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=core.openmcp.cloud,resources=managedcontrolplanes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core.openmcp.cloud,resources=managedcontrolplanes/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=core.openmcp.cloud,resources=managedcontrolplanes/finalizers,verbs=update
+// +kubebuilder:rbac:groups=core.openmcp.cloud,resources=projects;workspaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=usage.openmcp.cloud,resources=chargingtargets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch;update
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -64,42 +77,88 @@ func (r *ManagedControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	re := regexp.MustCompile("project-(.+)--ws-(.+)")
-	namespace := mcp.Namespace
-	matches := re.FindStringSubmatch(namespace)
-	if len(matches) != 3 {
-		err := errors.New("namespace of mcp is invalid")
+	project, workspace, err := parseNamespace(mcp.Namespace)
+	if err != nil {
 		log.Error(err, "namespace of mcp is invalid")
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
-	project := matches[1]
-	workspace := matches[2]
 
 	log.Info("reconcile", "mcp", mcp.Name, "status", string(mcp.Status.Status))
 
-	if mcp.GetDeletionTimestamp() != nil || mcp.Status.Status == corev1alpha1.MCPStatusDeleting {
+	if mcp.GetDeletionTimestamp() != nil {
 		log.Info("mcp was deleted", "mcp", mcp.Name)
-		err := r.UsageTracker.DeletionEvent(ctx, project, workspace, mcp.Name)
+		tracked := controllerutil.ContainsFinalizer(&mcp, api.UsageFinalizer)
+		if err := releaseManagedControlPlane(ctx, r.Client, r.UsageTracker, project, workspace, &mcp); err != nil {
+			log.Error(err, "error when releasing mcp")
+			r.event(&mcp, corev1.EventTypeWarning, "UsageTrackingFailed", "MarkDeleted", "error when capturing the deletion: %v", err)
+			return ctrl.Result{}, err
+		}
+		if tracked {
+			r.event(&mcp, corev1.EventTypeNormal, "DeletionCaptured", "MarkDeleted", "usage captured until the deletion at %s", mcp.GetDeletionTimestamp().UTC().Format(time.RFC3339))
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if mcp.Status.Status == corev1alpha1.MCPStatusDeleting {
+		log.Info("mcp is deleting", "mcp", mcp.Name)
+		err := r.UsageTracker.DeletionEvent(ctx, project, workspace, mcp.Name, mcp.UID)
 		if err != nil {
 			log.Error(err, "error when tracking deletion")
+			r.event(&mcp, corev1.EventTypeWarning, "UsageTrackingFailed", "MarkDeleted", "error when capturing the deletion: %v", err)
 			return ctrl.Result{}, client.IgnoreNotFound(err)
 		}
 		return ctrl.Result{}, nil
 	}
 
-	err = r.UsageTracker.CreateOrUpdateEvent(ctx, project, workspace, mcp.Name)
+	if !controllerutil.ContainsFinalizer(&mcp, api.UsageFinalizer) {
+		patch := client.MergeFromWithOptions(mcp.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		controllerutil.AddFinalizer(&mcp, api.UsageFinalizer)
+		if err := r.Patch(ctx, &mcp, patch); err != nil {
+			log.Error(err, "error when adding finalizer to mcp")
+			return ctrl.Result{}, err
+		}
+		r.event(&mcp, corev1.EventTypeNormal, "UsageTracked", "Track", "usage of the mcp is tracked by the usage-operator")
+	}
+
+	err = r.UsageTracker.CreateOrUpdateEvent(ctx, project, workspace, mcp.Name, mcp.UID, string(mcp.Status.Status))
 	if err != nil {
 		log.Error(err, "error when tracking create or ignore of mcp")
+		r.event(&mcp, corev1.EventTypeWarning, "UsageTrackingFailed", "Track", "error when tracking the usage: %v", err)
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
 	return ctrl.Result{}, nil
 }
 
-// SetupWithManager sets up the controller with the Manager.
+// event records an event for the given mcp, if the reconciler has an event recorder.
+func (r *ManagedControlPlaneReconciler) event(mcp *corev1alpha1.ManagedControlPlane, eventType, reason, action, note string, args ...any) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(mcp, nil, eventType, reason, action, note, args...)
+}
+
+// metadataChanged filters the events of projects and workspaces, as the charging target is read from their labels
+// or annotations.
+var metadataChanged = predicate.Or(predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{})
+
+// SetupWithManager sets up the controller with the Manager. Label and annotation changes of projects and workspaces
+// reconcile all their mcps, so the charging targets are resolved again right away. Changes of a registered charging
+// target reconcile the mcps which use it.
 func (r *ManagedControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1alpha1.ManagedControlPlane{}).
+		Watches(&pwcorev1alpha1.Project{},
+			handler.EnqueueRequestsFromMapFunc(r.projectToManagedControlPlanes),
+			builder.WithPredicates(metadataChanged),
+		).
+		Watches(&pwcorev1alpha1.Workspace{},
+			handler.EnqueueRequestsFromMapFunc(r.workspaceToManagedControlPlanes),
+			builder.WithPredicates(metadataChanged),
+		).
+		Watches(&v2.ChargingTarget{},
+			handler.EnqueueRequestsFromMapFunc(r.chargingTargetToManagedControlPlanes),
+		).
 		Named("managedcontrolplane").
 		Complete(r)
 }
@@ -24,13 +24,15 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	corev1alpha1 "github.com/openmcp-project/mcp-operator/api/core/v1alpha1"
 	pwcorev1alpha1 "github.com/openmcp-project/project-workspace-operator/api/core/v1alpha1"
 
-	v1 "github.com/openmcp-project/usage-operator/api/usage/v1"
+	"github.com/openmcp-project/usage-operator/api"
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
 )
 
 const (
@@ -101,7 +103,7 @@ var _ = Describe("ManagedControlPlane Controller", Ordered, func() {
 		It("should create a mcp usage resource based on a ManagedControlPlane resource", func() {
 			ctx := context.Background()
 
-			var mcpUsages v1.MCPUsageList
+			var mcpUsages v2.MCPUsageList
 			Eventually(func(g Gomega) {
 				g.Expect(k8sClient.List(ctx, &mcpUsages)).To(Succeed())
 
@@ -109,27 +111,61 @@ var _ = Describe("ManagedControlPlane Controller", Ordered, func() {
 
 				mcpUsageName = mcpUsages.Items[0].Name
 
-				g.Expect(mcpUsages.Items[0].Spec.ChargingTarget).Should(Equal(ChargingTarget))
+				g.Expect(mcpUsages.Items[0].Status.UsageOperator.ChargingTarget).Should(Equal(ChargingTarget))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("should add the usage finalizer to the ManagedControlPlane", func() {
+			ctx := context.Background()
+
+			Eventually(func(g Gomega) {
+				var mcp corev1alpha1.ManagedControlPlane
+				g.Expect(k8sClient.Get(ctx, client.ObjectKey{
+					Name:      strings.ToLower(MCPName),
+					Namespace: workspaceNamespaceName,
+				}, &mcp)).Should(Succeed())
+
+				g.Expect(mcp.Finalizers).Should(ContainElement(api.UsageFinalizer))
 			}, timeout, interval).Should(Succeed())
 		})
 
 		It("should have set the right charging target", func() {
 			ctx := context.Background()
 
-			mcpUsage := v1.MCPUsage{
+			mcpUsage := v2.MCPUsage{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: mcpUsageName,
 				},
 			}
 			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&mcpUsage), &mcpUsage)).Should(Succeed())
 
-			Expect(mcpUsage.Spec.ChargingTarget).Should(Equal(ChargingTarget))
+			Expect(mcpUsage.Status.UsageOperator.ChargingTarget).Should(Equal(ChargingTarget))
+		})
+
+		It("should update the charging target when the workspace is relabeled", func() {
+			ctx := context.Background()
+
+			var workspace pwcorev1alpha1.Workspace
+			Expect(k8sClient.Get(ctx, client.ObjectKey{
+				Name:      WorkspaceName,
+				Namespace: projectNamespaceName,
+			}, &workspace)).Should(Succeed())
+			workspace.Labels = map[string]string{
+				"openmcp.cloud.sap/charging-target": "87654321",
+			}
+			Expect(k8sClient.Update(ctx, &workspace)).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				var mcpUsage v2.MCPUsage
+				g.Expect(k8sClient.Get(ctx, client.ObjectKey{Name: mcpUsageName}, &mcpUsage)).Should(Succeed())
+				g.Expect(mcpUsage.Status.UsageOperator.ChargingTarget).Should(Equal("87654321"))
+			}, timeout, interval).Should(Succeed())
 		})
 
 		It("should mark a mcp usage resource as deleted when ManagedControlPlane is deleted", func() {
 			ctx := context.Background()
 
-			mcpUsage := v1.MCPUsage{
+			mcpUsage := v2.MCPUsage{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: mcpUsageName,
 				},
@@ -144,13 +180,34 @@ var _ = Describe("ManagedControlPlane Controller", Ordered, func() {
 			mcp.Status.Status = corev1alpha1.MCPStatusDeleting
 			Expect(k8sClient.Status().Update(ctx, &mcp)).Should(Succeed())
 
-			var mcpUsages v1.MCPUsageList
+			var mcpUsages v2.MCPUsageList
 			Eventually(func(g Gomega) {
 				g.Expect(k8sClient.List(ctx, &mcpUsages)).To(Succeed())
 
 				g.Expect(mcpUsages.Items).Should(HaveLen(1))
 
-				g.Expect(mcpUsages.Items[0].Spec.MCPDeletedAt.IsZero()).Should(BeFalse())
+				g.Expect(mcpUsages.Items[0].Status.UsageOperator.MCPDeletedAt.IsZero()).Should(BeFalse())
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("should remove the usage finalizer after the deletion was captured", func() {
+			ctx := context.Background()
+
+			var mcp corev1alpha1.ManagedControlPlane
+			Expect(k8sClient.Get(ctx, client.ObjectKey{
+				Name:      strings.ToLower(MCPName),
+				Namespace: workspaceNamespaceName,
+			}, &mcp)).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, &mcp)).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&mcp), &mcp)
+				g.Expect(apierrors.IsNotFound(err)).Should(BeTrue())
+
+				var mcpUsages v2.MCPUsageList
+				g.Expect(k8sClient.List(ctx, &mcpUsages)).To(Succeed())
+				g.Expect(mcpUsages.Items).Should(HaveLen(1))
+				g.Expect(mcpUsages.Items[0].Status.UsageOperator.MCPDeletedAt.IsZero()).Should(BeFalse())
 			}, timeout, interval).Should(Succeed())
 		})
 	})
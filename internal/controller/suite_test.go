@@ -37,7 +37,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
-	v1 "github.com/openmcp-project/usage-operator/api/usage/v1"
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
 	"github.com/openmcp-project/usage-operator/internal/usage"
 	// +kubebuilder:scaffold:imports
 )
@@ -69,7 +69,7 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 	err = pwcorev1alpha1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
-	err = v1.AddToScheme(scheme.Scheme)
+	err = v2.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
 
 	// +kubebuilder:scaffold:scheme
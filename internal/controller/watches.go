@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1alpha1 "github.com/openmcp-project/mcp-operator/api/core/v1alpha1"
+	pwcorev1alpha1 "github.com/openmcp-project/project-workspace-operator/api/core/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+	"github.com/openmcp-project/usage-operator/internal/usage"
+)
+
+const projectNamespacePrefix = "project-"
+
+// projectToManagedControlPlanes maps a project to the mcps of all its workspaces.
+func (r *ManagedControlPlaneReconciler) projectToManagedControlPlanes(ctx context.Context, obj client.Object) []reconcile.Request {
+	log := logr.FromContextOrDiscard(ctx)
+
+	var workspaces pwcorev1alpha1.WorkspaceList
+	if err := r.List(ctx, &workspaces, client.InNamespace(projectNamespacePrefix+obj.GetName())); err != nil {
+		log.Error(err, "unable to list workspaces of project", "project", obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, workspace := range workspaces.Items {
+		requests = append(requests, r.managedControlPlaneRequests(ctx, obj.GetName(), workspace.Name)...)
+	}
+	return requests
+}
+
+// workspaceToManagedControlPlanes maps a workspace to its mcps.
+func (r *ManagedControlPlaneReconciler) workspaceToManagedControlPlanes(ctx context.Context, obj client.Object) []reconcile.Request {
+	project, ok := strings.CutPrefix(obj.GetNamespace(), projectNamespacePrefix)
+	if !ok {
+		return nil
+	}
+	return r.managedControlPlaneRequests(ctx, project, obj.GetName())
+}
+
+// chargingTargetToManagedControlPlanes maps a registered charging target to the mcps, which use it directly or as a
+// share of a weighted charging target, and to the mcps with an invalid charging target, as it might be registered now.
+func (r *ManagedControlPlaneReconciler) chargingTargetToManagedControlPlanes(ctx context.Context, obj client.Object) []reconcile.Request {
+	log := logr.FromContextOrDiscard(ctx)
+
+	var mcpUsages v2.MCPUsageList
+	if err := r.List(ctx, &mcpUsages); err != nil {
+		log.Error(err, "unable to list mcp usages of charging target", "chargingTarget", obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, mcpUsage := range mcpUsages.Items {
+		status := mcpUsage.Status.UsageOperator
+		if !status.MCPDeletedAt.IsZero() {
+			continue
+		}
+		uses := status.ChargingTarget == obj.GetName() || slices.ContainsFunc(status.ChargingTargetSplit, func(share v2.ChargingTargetShare) bool {
+			return share.ChargingTarget == obj.GetName()
+		})
+		if !uses && !meta.IsStatusConditionFalse(status.Conditions, v2.ConditionChargingTargetValid) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKey{
+			Namespace: usage.GetNamespacedName(mcpUsage.Spec.Project, mcpUsage.Spec.Workspace),
+			Name:      mcpUsage.Spec.MCP,
+		}})
+	}
+	return requests
+}
+
+// managedControlPlaneRequests returns a request for every mcp of the given workspace.
+func (r *ManagedControlPlaneReconciler) managedControlPlaneRequests(ctx context.Context, project, workspace string) []reconcile.Request {
+	log := logr.FromContextOrDiscard(ctx)
+
+	var mcps corev1alpha1.ManagedControlPlaneList
+	if err := r.List(ctx, &mcps, client.InNamespace(usage.GetNamespacedName(project, workspace))); err != nil {
+		log.Error(err, "unable to list mcps of workspace", "project", project, "workspace", workspace)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(mcps.Items))
+	for _, mcp := range mcps.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&mcp)})
+	}
+	return requests
+}
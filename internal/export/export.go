@@ -0,0 +1,146 @@
+// Package export converts the daily usage of MCPUsages into records for file-based billing pipelines.
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+	"github.com/openmcp-project/usage-operator/internal/usage"
+)
+
+// Record is the usage of an MCP on a day, which is charged to a charging target. A day of an MCP with a weighted
+// charging target results in one record per charging target.
+type Record struct {
+	Date               string  `json:"date"`
+	Project            string  `json:"project"`
+	Workspace          string  `json:"workspace"`
+	MCP                string  `json:"mcp"`
+	MCPUsage           string  `json:"mcp_usage"`
+	ChargingTarget     string  `json:"charging_target"`
+	ChargingTargetType string  `json:"charging_target_type"`
+	Hours              float64 `json:"hours"`
+	NonBillableHours   float64 `json:"non_billable_hours"`
+}
+
+// Cursor remembers the last exported day of every MCPUsage, so an export only contains the days, which were not
+// exported before.
+type Cursor struct {
+	// MCPUsages maps the name of an MCPUsage to the date of its last exported day.
+	MCPUsages map[string]string `json:"mcp_usages"`
+}
+
+// LoadCursor reads the cursor from the given file. A missing file is an empty cursor.
+func LoadCursor(path string) (Cursor, error) {
+	cursor := Cursor{MCPUsages: map[string]string{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cursor, nil
+	} else if err != nil {
+		return cursor, fmt.Errorf("error reading cursor file: %w", err)
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, fmt.Errorf("error parsing cursor file %s: %w", path, err)
+	}
+	if cursor.MCPUsages == nil {
+		cursor.MCPUsages = map[string]string{}
+	}
+	return cursor, nil
+}
+
+// Save writes the cursor to the given file. The file is replaced atomically, so an interrupted export never leaves a
+// broken cursor behind.
+func (c Cursor) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling cursor: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("error creating cursor file: %w", err)
+	}
+	// the temporary file is gone after a successful rename
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("error writing cursor file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing cursor file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error replacing cursor file: %w", err)
+	}
+	return nil
+}
+
+// Records returns the records of all closed days, which are after the cursor, and the cursor after these records.
+// Days which are still open are left for a later export, as their usage can still change. MCPUsages which don't exist
+// anymore are removed from the cursor. The records are sorted by date, project, workspace, mcp and charging target.
+func Records(mcpUsages []v2.MCPUsage, cursor Cursor, billingLocation *time.Location, now time.Time) ([]Record, Cursor) {
+	next := Cursor{MCPUsages: map[string]string{}}
+	var records []Record
+	for i := range mcpUsages {
+		mcpUsage := &mcpUsages[i]
+		exported, ok := cursor.MCPUsages[mcpUsage.Name]
+		if ok {
+			next.MCPUsages[mcpUsage.Name] = exported
+		}
+
+		days := slices.Clone(mcpUsage.Status.UsageOperator.Usage)
+		slices.SortFunc(days, func(a, b v2.DailyUsage) int { return a.Date.Compare(b.Date.Time) })
+		for _, day := range days {
+			date := usage.BillingDate(mcpUsage, day, billingLocation)
+			if date <= exported {
+				continue
+			}
+			// the days are captured in order, so all later days are open as well
+			if !usage.IsDayClosed(mcpUsage, day, billingLocation, now) {
+				break
+			}
+
+			// the usage, which isn't attributed to a charging target, is exported with an empty charging target, so the
+			// cursor never moves past usage, which wasn't exported
+			remainder := v2.ChargingTargetUsage{Usage: day.Usage, NonBillableUsage: day.NonBillableUsage}
+			for _, chargingTarget := range usage.ChargingTargetUsages(mcpUsage, day) {
+				records = append(records, record(mcpUsage, date, chargingTarget))
+				remainder.Usage.Duration -= chargingTarget.Usage.Duration
+				remainder.NonBillableUsage.Duration -= chargingTarget.NonBillableUsage.Duration
+			}
+			if remainder.Usage.Duration > 0 || remainder.NonBillableUsage.Duration > 0 {
+				remainder.Usage.Duration = max(remainder.Usage.Duration, 0)
+				remainder.NonBillableUsage.Duration = max(remainder.NonBillableUsage.Duration, 0)
+				records = append(records, record(mcpUsage, date, remainder))
+			}
+			next.MCPUsages[mcpUsage.Name] = date
+		}
+	}
+
+	slices.SortStableFunc(records, func(a, b Record) int {
+		return strings.Compare(
+			strings.Join([]string{a.Date, a.Project, a.Workspace, a.MCP, a.MCPUsage, a.ChargingTarget}, "/"),
+			strings.Join([]string{b.Date, b.Project, b.Workspace, b.MCP, b.MCPUsage, b.ChargingTarget}, "/"),
+		)
+	})
+	return records, next
+}
+
+func record(mcpUsage *v2.MCPUsage, date string, chargingTarget v2.ChargingTargetUsage) Record {
+	return Record{
+		Date:               date,
+		Project:            mcpUsage.Spec.Project,
+		Workspace:          mcpUsage.Spec.Workspace,
+		MCP:                mcpUsage.Spec.MCP,
+		MCPUsage:           mcpUsage.Name,
+		ChargingTarget:     chargingTarget.ChargingTarget,
+		ChargingTargetType: chargingTarget.ChargingTargetType,
+		Hours:              chargingTarget.Usage.Hours(),
+		NonBillableHours:   chargingTarget.NonBillableUsage.Hours(),
+	}
+}
@@ -0,0 +1,192 @@
+package export
+
+import (
+	"bytes"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+)
+
+// parquetRow is a row of the parquet export as read by a parquet reader.
+type parquetRow struct {
+	Date               time.Time `parquet:"date,date"`
+	Project            string    `parquet:"project"`
+	Workspace          string    `parquet:"workspace"`
+	MCP                string    `parquet:"mcp"`
+	MCPUsage           string    `parquet:"mcp_usage"`
+	ChargingTarget     string    `parquet:"charging_target"`
+	ChargingTargetType string    `parquet:"charging_target_type"`
+	Hours              float64   `parquet:"hours"`
+	NonBillableHours   float64   `parquet:"non_billable_hours"`
+}
+
+func dailyUsage(d int, usage time.Duration) v2.DailyUsage {
+	return v2.DailyUsage{
+		Date:  metav1.NewTime(time.Date(2025, 7, d, 0, 0, 0, 0, time.UTC)),
+		Usage: metav1.Duration{Duration: usage},
+	}
+}
+
+var _ = Describe("Export", func() {
+	var mcpUsages []v2.MCPUsage
+	now := time.Date(2025, 7, 3, 12, 0, 0, 0, time.UTC)
+
+	BeforeEach(func() {
+		mcpUsages = []v2.MCPUsage{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "usage-1"},
+				Spec:       v2.MCPUsageSpec{Project: "project", Workspace: "workspace", MCP: "mcp-1"},
+				Status: v2.MCPUsageStatus{UsageOperator: v2.UsageOperatorStatus{
+					ChargingTarget:    "cc-1:50,cc-2:50",
+					LastUsageCaptured: metav1.NewTime(time.Date(2025, 7, 3, 12, 0, 0, 0, time.UTC)),
+					// the days are not necessarily sorted
+					Usage: []v2.DailyUsage{dailyUsage(3, 12*time.Hour), dailyUsage(2, 24*time.Hour), dailyUsage(1, 10*time.Hour)},
+				}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "usage-2"},
+				Spec:       v2.MCPUsageSpec{Project: "project", Workspace: "workspace", MCP: "mcp-2"},
+				Status: v2.MCPUsageStatus{UsageOperator: v2.UsageOperatorStatus{
+					ChargingTarget:    "cc-3",
+					LastUsageCaptured: metav1.NewTime(time.Date(2025, 7, 1, 8, 0, 0, 0, time.UTC)),
+					MCPDeletedAt:      metav1.NewTime(time.Date(2025, 7, 1, 8, 0, 0, 0, time.UTC)),
+					Usage:             []v2.DailyUsage{dailyUsage(1, 8*time.Hour)},
+				}},
+			},
+		}
+	})
+
+	It("should export one record per mcp, day and charging target of the closed days", func() {
+		records, cursor := Records(mcpUsages, Cursor{}, time.UTC, now)
+
+		Expect(records).Should(Equal([]Record{
+			{Date: "2025-07-01", Project: "project", Workspace: "workspace", MCP: "mcp-1", MCPUsage: "usage-1", ChargingTarget: "cc-1", Hours: 5},
+			{Date: "2025-07-01", Project: "project", Workspace: "workspace", MCP: "mcp-1", MCPUsage: "usage-1", ChargingTarget: "cc-2", Hours: 5},
+			{Date: "2025-07-01", Project: "project", Workspace: "workspace", MCP: "mcp-2", MCPUsage: "usage-2", ChargingTarget: "cc-3", Hours: 8},
+			{Date: "2025-07-02", Project: "project", Workspace: "workspace", MCP: "mcp-1", MCPUsage: "usage-1", ChargingTarget: "cc-1", Hours: 12},
+			{Date: "2025-07-02", Project: "project", Workspace: "workspace", MCP: "mcp-1", MCPUsage: "usage-1", ChargingTarget: "cc-2", Hours: 12},
+		}))
+		Expect(cursor.MCPUsages).Should(Equal(map[string]string{"usage-1": "2025-07-02", "usage-2": "2025-07-01"}))
+	})
+
+	It("should export the usage of a closed day without a charging target with an empty charging target", func() {
+		mcpUsages[1].Status.UsageOperator.ChargingTarget = ""
+
+		records, cursor := Records(mcpUsages[1:], Cursor{}, time.UTC, now)
+		Expect(records).Should(Equal([]Record{
+			{Date: "2025-07-01", Project: "project", Workspace: "workspace", MCP: "mcp-2", MCPUsage: "usage-2", Hours: 8},
+		}))
+		Expect(cursor.MCPUsages).Should(Equal(map[string]string{"usage-2": "2025-07-01"}))
+	})
+
+	It("should only export the days after the cursor", func() {
+		_, cursor := Records(mcpUsages, Cursor{}, time.UTC, now)
+
+		records, next := Records(mcpUsages, cursor, time.UTC, now)
+		Expect(records).Should(BeEmpty())
+		Expect(next).Should(Equal(cursor))
+
+		// the next day was captured
+		mcpUsages[0].Status.UsageOperator.LastUsageCaptured = metav1.NewTime(time.Date(2025, 7, 4, 1, 0, 0, 0, time.UTC))
+		records, next = Records(mcpUsages, cursor, time.UTC, now.Add(24*time.Hour))
+		Expect(records).Should(HaveLen(2))
+		Expect(records[0].Date).Should(Equal("2025-07-03"))
+		Expect(next.MCPUsages["usage-1"]).Should(Equal("2025-07-03"))
+	})
+
+	It("should remove mcp usages from the cursor, which don't exist anymore", func() {
+		_, cursor := Records(mcpUsages, Cursor{MCPUsages: map[string]string{"gone": "2025-06-30"}}, time.UTC, now)
+		Expect(cursor.MCPUsages).ShouldNot(HaveKey("gone"))
+	})
+
+	It("should save and load the cursor", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "cursor.json")
+
+		cursor, err := LoadCursor(path)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(cursor.MCPUsages).Should(BeEmpty())
+
+		cursor.MCPUsages["usage-1"] = "2025-07-02"
+		Expect(cursor.Save(path)).Should(Succeed())
+
+		loaded, err := LoadCursor(path)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(loaded).Should(Equal(cursor))
+	})
+
+	Context("Formats", func() {
+		records := []Record{
+			{Date: "2025-07-01", Project: "project", Workspace: "workspace", MCP: "mcp-1", MCPUsage: "usage-1", ChargingTarget: "cc-1", Hours: 5.5},
+		}
+
+		It("should write csv", func() {
+			var out bytes.Buffer
+			Expect(Write(&out, records, FormatCSV)).Should(Succeed())
+			Expect(out.String()).Should(Equal("date,project,workspace,mcp,mcp_usage,charging_target,charging_target_type,hours,non_billable_hours\n" +
+				"2025-07-01,project,workspace,mcp-1,usage-1,cc-1,,5.5,0\n"))
+		})
+
+		It("should write json lines", func() {
+			var out bytes.Buffer
+			Expect(Write(&out, records, FormatJSONL)).Should(Succeed())
+			Expect(out.String()).Should(Equal(`{"date":"2025-07-01","project":"project","workspace":"workspace","mcp":"mcp-1","mcp_usage":"usage-1","charging_target":"cc-1","charging_target_type":"","hours":5.5,"non_billable_hours":0}` + "\n"))
+		})
+
+		It("should write parquet, which a parquet reader can read", func() {
+			records := []Record{
+				{Date: "2025-07-01", Project: "project", Workspace: "workspace", MCP: "mcp-1", MCPUsage: "usage-1", ChargingTarget: "cc-1", Hours: 5.5},
+				{Date: "2025-07-02", Project: "project", Workspace: "workspace", MCP: "mcp-2", MCPUsage: "usage-2", ChargingTarget: "cc-2",
+					ChargingTargetType: "cost-center", Hours: 20, NonBillableHours: 4},
+			}
+			var out bytes.Buffer
+			Expect(Write(&out, records, FormatParquet)).Should(Succeed())
+
+			file, err := parquet.OpenFile(bytes.NewReader(out.Bytes()), int64(out.Len()))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			var columns []string
+			for _, field := range file.Schema().Fields() {
+				Expect(field.Optional() || field.Repeated()).Should(BeFalse())
+				columns = append(columns, field.Name()+":"+field.Type().String())
+			}
+			Expect(columns).Should(Equal([]string{
+				"date:DATE", "project:STRING", "workspace:STRING", "mcp:STRING", "mcp_usage:STRING",
+				"charging_target:STRING", "charging_target_type:STRING", "hours:DOUBLE", "non_billable_hours:DOUBLE",
+			}))
+			Expect(file.NumRows()).Should(Equal(int64(2)))
+			Expect(file.RowGroups()).Should(HaveLen(1))
+			for _, chunk := range file.Metadata().RowGroups[0].Columns {
+				Expect(chunk.MetaData.Codec).Should(Equal(format.Uncompressed))
+			}
+
+			rows, err := parquet.Read[parquetRow](bytes.NewReader(out.Bytes()), int64(out.Len()))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(rows).Should(Equal([]parquetRow{
+				{Date: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), Project: "project", Workspace: "workspace", MCP: "mcp-1", MCPUsage: "usage-1", ChargingTarget: "cc-1", Hours: 5.5},
+				{Date: time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC), Project: "project", Workspace: "workspace", MCP: "mcp-2", MCPUsage: "usage-2", ChargingTarget: "cc-2",
+					ChargingTargetType: "cost-center", Hours: 20, NonBillableHours: 4},
+			}))
+		})
+
+		It("should write an empty parquet file, which a parquet reader can read", func() {
+			var out bytes.Buffer
+			Expect(Write(&out, nil, FormatParquet)).Should(Succeed())
+
+			file, err := parquet.OpenFile(bytes.NewReader(out.Bytes()), int64(out.Len()))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(file.NumRows()).Should(BeZero())
+			Expect(file.Schema().Fields()).Should(HaveLen(9))
+		})
+
+		It("should reject an unknown format", func() {
+			Expect(Write(&bytes.Buffer{}, records, "xml")).ShouldNot(Succeed())
+		})
+	})
+})
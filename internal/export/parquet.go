@@ -0,0 +1,45 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetRecord is a record as row of the parquet file. The date is stored with the DATE logical type as days since
+// the unix epoch, so it is read as a date and not as a string. All columns are required.
+type parquetRecord struct {
+	Date               int32   `parquet:"date,date"`
+	Project            string  `parquet:"project"`
+	Workspace          string  `parquet:"workspace"`
+	MCP                string  `parquet:"mcp"`
+	MCPUsage           string  `parquet:"mcp_usage"`
+	ChargingTarget     string  `parquet:"charging_target"`
+	ChargingTargetType string  `parquet:"charging_target_type"`
+	Hours              float64 `parquet:"hours"`
+	NonBillableHours   float64 `parquet:"non_billable_hours"`
+}
+
+func writeParquet(w io.Writer, records []Record) error {
+	rows := make([]parquetRecord, 0, len(records))
+	for _, r := range records {
+		date, err := time.Parse(time.DateOnly, r.Date)
+		if err != nil {
+			return fmt.Errorf("invalid date %q of MCPUsage %s: %w", r.Date, r.MCPUsage, err)
+		}
+		rows = append(rows, parquetRecord{
+			Date:               int32(date.Unix() / int64(24*time.Hour/time.Second)),
+			Project:            r.Project,
+			Workspace:          r.Workspace,
+			MCP:                r.MCP,
+			MCPUsage:           r.MCPUsage,
+			ChargingTarget:     r.ChargingTarget,
+			ChargingTargetType: r.ChargingTargetType,
+			Hours:              r.Hours,
+			NonBillableHours:   r.NonBillableHours,
+		})
+	}
+	return parquet.Write(w, rows, parquet.CreatedBy("usage-operator", "", ""))
+}
@@ -0,0 +1,61 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatJSONL   = "jsonl"
+	FormatParquet = "parquet"
+)
+
+// Formats are the supported file formats of an export.
+var Formats = []string{FormatCSV, FormatJSONL, FormatParquet}
+
+// csvHeader are the columns of the csv format, they match the json fields of a record.
+var csvHeader = []string{"date", "project", "workspace", "mcp", "mcp_usage", "charging_target", "charging_target_type", "hours", "non_billable_hours"}
+
+// Write writes the records in the given format.
+func Write(w io.Writer, records []Record, format string) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, records)
+	case FormatJSONL:
+		encoder := json.NewEncoder(w)
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatParquet:
+		return writeParquet(w, records)
+	default:
+		return fmt.Errorf("unsupported export format %q, must be one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+func writeCSV(w io.Writer, records []Record) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, r := range records {
+		err := cw.Write([]string{
+			r.Date, r.Project, r.Workspace, r.MCP, r.MCPUsage, r.ChargingTarget, r.ChargingTargetType,
+			strconv.FormatFloat(r.Hours, 'f', -1, 64),
+			strconv.FormatFloat(r.NonBillableHours, 'f', -1, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,58 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
+
+	pwcorev1alpha1 "github.com/openmcp-project/project-workspace-operator/api/core/v1alpha1"
+)
+
+// labelBillingTimezone contains the IANA timezone, in which the usage of a charging target is billed. As label values
+// can't contain slashes, they are replaced by dots, e.g. "Europe.Berlin" or "America.New_York".
+const labelBillingTimezone = "openmcp.cloud.sap/billing-timezone"
+
+// ResolveBillingTimezone returns the billing timezone of the given workspace. The label of the workspace overrides the
+// label of the project. If neither is set, an empty string is returned.
+func ResolveBillingTimezone(ctx context.Context, client k8s.Client, projectName string, workspaceName string) (string, error) {
+	var project pwcorev1alpha1.Project
+	var workspace pwcorev1alpha1.Workspace
+
+	err := client.Get(ctx, k8s.ObjectKey{
+		Name: projectName,
+	}, &project)
+	if errors.IsNotFound(err) {
+		return "", fmt.Errorf("cant find project %v: %w", projectName, err)
+	} else if err != nil {
+		return "", fmt.Errorf("error when getting project %v: %w", projectName, err)
+	}
+
+	err = client.Get(ctx, k8s.ObjectKey{
+		Name:      workspaceName,
+		Namespace: fmt.Sprintf("project-%s", projectName),
+	}, &workspace)
+	if errors.IsNotFound(err) {
+		return "", fmt.Errorf("cant find workspace %v: %w", workspaceName, err)
+	} else if err != nil {
+		return "", fmt.Errorf("error when getting workspace %v: %w", workspaceName, err)
+	}
+
+	billingTimezone := project.GetLabels()[labelBillingTimezone]
+	if wsBillingTimezone, ok := workspace.GetLabels()[labelBillingTimezone]; ok {
+		billingTimezone = wsBillingTimezone
+	}
+	if billingTimezone == "" {
+		return "", nil
+	}
+
+	billingTimezone = strings.ReplaceAll(billingTimezone, ".", "/")
+	if _, err := time.LoadLocation(billingTimezone); err != nil {
+		return "", fmt.Errorf("invalid billing timezone %q for project(%s) workspace(%s): %w", billingTimezone, projectName, workspaceName, err)
+	}
+
+	return billingTimezone, nil
+}
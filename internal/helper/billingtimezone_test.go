@@ -0,0 +1,94 @@
+package helper
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pwcorev1alpha1 "github.com/openmcp-project/project-workspace-operator/api/core/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	TimezoneProjectName   = "tz-project"
+	TimezoneWorkspaceName = "tz-workspace"
+)
+
+var _ = Describe("Billing Timezone Resolver", Ordered, func() {
+	BeforeAll(func() {
+		ctx := context.Background()
+		Expect(k8sClient.Create(ctx, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "project-" + TimezoneProjectName,
+			},
+		})).To(Succeed())
+
+		project := pwcorev1alpha1.Project{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: TimezoneProjectName,
+			},
+		}
+		Expect(k8sClient.Create(ctx, &project)).To(Succeed())
+		workspace := pwcorev1alpha1.Workspace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      TimezoneWorkspaceName,
+				Namespace: "project-" + TimezoneProjectName,
+			},
+		}
+		Expect(k8sClient.Create(ctx, &workspace)).To(Succeed())
+	})
+
+	It("Should resolve no billing timezone, if none is set", func() {
+		ctx := context.Background()
+		billingTimezone, err := ResolveBillingTimezone(ctx, k8sClient, TimezoneProjectName, TimezoneWorkspaceName)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(billingTimezone).Should(BeEmpty())
+	})
+
+	It("Should resolve the project billing timezone", func() {
+		ctx := context.Background()
+
+		project := pwcorev1alpha1.Project{}
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Name: TimezoneProjectName}, &project)).Should(Succeed())
+		project.SetLabels(map[string]string{
+			labelBillingTimezone: "Europe.Berlin",
+		})
+		Expect(k8sClient.Update(ctx, &project)).Should(Succeed())
+
+		billingTimezone, err := ResolveBillingTimezone(ctx, k8sClient, TimezoneProjectName, TimezoneWorkspaceName)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(billingTimezone).Should(Equal("Europe/Berlin"))
+	})
+
+	It("Should resolve the workspace billing timezone, if set", func() {
+		ctx := context.Background()
+
+		workspace := pwcorev1alpha1.Workspace{}
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Name: TimezoneWorkspaceName, Namespace: "project-" + TimezoneProjectName}, &workspace)).Should(Succeed())
+		workspace.SetLabels(map[string]string{
+			labelBillingTimezone: "America.New_York",
+		})
+		Expect(k8sClient.Update(ctx, &workspace)).Should(Succeed())
+
+		billingTimezone, err := ResolveBillingTimezone(ctx, k8sClient, TimezoneProjectName, TimezoneWorkspaceName)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(billingTimezone).Should(Equal("America/New_York"))
+	})
+
+	It("Should reject an unknown billing timezone", func() {
+		ctx := context.Background()
+
+		workspace := pwcorev1alpha1.Workspace{}
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Name: TimezoneWorkspaceName, Namespace: "project-" + TimezoneProjectName}, &workspace)).Should(Succeed())
+		workspace.SetLabels(map[string]string{
+			labelBillingTimezone: "Mars.Olympus_Mons",
+		})
+		Expect(k8sClient.Update(ctx, &workspace)).Should(Succeed())
+
+		_, err := ResolveBillingTimezone(ctx, k8sClient, TimezoneProjectName, TimezoneWorkspaceName)
+		Expect(err).Should(HaveOccurred())
+	})
+})
@@ -3,18 +3,133 @@ package helper
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	k8s "sigs.k8s.io/controller-runtime/pkg/client"
 
 	mcpcorev1alpha1 "github.com/openmcp-project/mcp-operator/api/core/v1alpha1"
 	pwcorev1alpha1 "github.com/openmcp-project/project-workspace-operator/api/core/v1alpha1"
+
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+	"github.com/openmcp-project/usage-operator/internal/config"
+)
+
+const (
+	// ReasonUnknown means, that no ChargingTarget is registered for the charging target.
+	ReasonUnknown = "Unknown"
+	// ReasonExpired means, that the ChargingTarget is not valid anymore.
+	ReasonExpired = "Expired"
+	// ReasonNotYetValid means, that the ChargingTarget is not valid yet.
+	ReasonNotYetValid = "NotYetValid"
+	// ReasonTypeMismatch means, that the type of the charging target differs from the type of the ChargingTarget.
+	ReasonTypeMismatch = "TypeMismatch"
 )
 
-const labelChargingTarget = "openmcp.cloud.sap/charging-target"
-const labelChargingTargetType = "openmcp.cloud.sap/charging-target-type"
+// InvalidChargingTargetError is returned, if a charging target is not registered by a valid ChargingTarget.
+type InvalidChargingTargetError struct {
+	ChargingTarget string
+	// Reason is a CamelCase reason, which can be used for conditions and events.
+	Reason  string
+	Message string
+}
+
+func (e *InvalidChargingTargetError) Error() string {
+	return fmt.Sprintf("charging target %q is invalid: %s", e.ChargingTarget, e.Message)
+}
+
+// ParseChargingTargetSplit parses a weighted charging target like "cc-1:70,cc-2:30". The weights are percentages and
+// must add up to 100. A single charging target without weight returns no shares.
+func ParseChargingTargetSplit(value string) ([]v2.ChargingTargetShare, error) {
+	if !strings.ContainsAny(value, ":,") {
+		return nil, nil
+	}
+
+	var shares []v2.ChargingTargetShare
+	var sum int32
+	for part := range strings.SplitSeq(value, ",") {
+		chargingTarget, rawWeight, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok || chargingTarget == "" {
+			return nil, fmt.Errorf("share %q of charging target %q must be formatted as <charging-target>:<weight>", part, value)
+		}
+		weight, err := strconv.ParseInt(rawWeight, 10, 32)
+		if err != nil || weight < 1 || weight > 100 {
+			return nil, fmt.Errorf("weight of %q in charging target %q must be a number between 1 and 100", chargingTarget, value)
+		}
+		if slices.ContainsFunc(shares, func(s v2.ChargingTargetShare) bool { return s.ChargingTarget == chargingTarget }) {
+			return nil, fmt.Errorf("charging target %q is listed twice in %q", chargingTarget, value)
+		}
+		shares = append(shares, v2.ChargingTargetShare{ChargingTarget: chargingTarget, Weight: int32(weight)})
+		sum += int32(weight)
+	}
+	if sum != 100 {
+		return nil, fmt.Errorf("weights of charging target %q must add up to 100, got %d", value, sum)
+	}
 
+	return shares, nil
+}
+
+// ChargingTargetShares returns the shares of the given charging target. A single charging target has one share with
+// the weight 100. If the charging target can't be parsed, it is treated as a single charging target.
+func ChargingTargetShares(value string) []v2.ChargingTargetShare {
+	shares, err := ParseChargingTargetSplit(value)
+	if err != nil || len(shares) == 0 {
+		return []v2.ChargingTargetShare{{ChargingTarget: value, Weight: 100}}
+	}
+	return shares
+}
+
+// ChargingTargetResolver resolves the charging target of an MCP from the metadata of its project, workspace and
+// the MCP itself.
+type ChargingTargetResolver struct {
+	config config.ChargingTargetConfig
+	rules  []chargingTargetRule
+}
+
+// ChargingTargetResolution is a resolved charging target together with the rule, which derived it.
+type ChargingTargetResolution struct {
+	ChargingTarget     string
+	ChargingTargetType string
+	// Rule is the name of the rule, which derived the charging target. It is empty for explicit charging targets.
+	Rule string
+}
+
+// NewChargingTargetResolver returns a resolver for the given configuration. The configuration must be defaulted.
+// An error is returned, if a charging target rule can't be compiled.
+func NewChargingTargetResolver(cfg config.ChargingTargetConfig) (*ChargingTargetResolver, error) {
+	rules, err := compileChargingTargetRules(cfg.Rules)
+	if err != nil {
+		return nil, err
+	}
+	return &ChargingTargetResolver{config: cfg, rules: rules}, nil
+}
+
+// DefaultChargingTargetResolver returns a resolver, which reads the default labels with the precedence
+// project < workspace < mcp.
+func DefaultChargingTargetResolver() *ChargingTargetResolver {
+	// the default configuration has no rules, so it always compiles
+	resolver, _ := NewChargingTargetResolver(config.New().ChargingTarget)
+	return resolver
+}
+
+// ResolveChargingTarget resolves the charging target with the default resolver.
 func ResolveChargingTarget(ctx context.Context, client k8s.Client, projectName string, workspaceName string, mcpName string) (string, string, error) {
+	return DefaultChargingTargetResolver().Resolve(ctx, client, projectName, workspaceName, mcpName)
+}
+
+// Resolve returns the charging target and its type.
+func (r *ChargingTargetResolver) Resolve(ctx context.Context, client k8s.Client, projectName string, workspaceName string, mcpName string) (string, string, error) {
+	resolution, err := r.Resolution(ctx, client, projectName, workspaceName, mcpName)
+	return resolution.ChargingTarget, resolution.ChargingTargetType, err
+}
+
+// Resolution resolves the charging target. The levels are evaluated in the configured precedence, so later levels
+// override earlier ones, until a level is locked. If no level has an explicit charging target, the rules are
+// evaluated in their order.
+func (r *ChargingTargetResolver) Resolution(ctx context.Context, client k8s.Client, projectName string, workspaceName string, mcpName string) (ChargingTargetResolution, error) {
 	var project pwcorev1alpha1.Project
 	var workspace pwcorev1alpha1.Workspace
 	var mcp mcpcorev1alpha1.ManagedControlPlane
@@ -23,9 +138,9 @@ func ResolveChargingTarget(ctx context.Context, client k8s.Client, projectName s
 		Name: projectName,
 	}, &project)
 	if errors.IsNotFound(err) {
-		return "", "", fmt.Errorf("cant find project %v: %w", projectName, err)
+		return ChargingTargetResolution{}, fmt.Errorf("cant find project %v: %w", projectName, err)
 	} else if err != nil {
-		return "", "", fmt.Errorf("error when getting project %v: %w", projectName, err)
+		return ChargingTargetResolution{}, fmt.Errorf("error when getting project %v: %w", projectName, err)
 	}
 
 	err = client.Get(ctx, k8s.ObjectKey{
@@ -33,9 +148,9 @@ func ResolveChargingTarget(ctx context.Context, client k8s.Client, projectName s
 		Namespace: fmt.Sprintf("project-%s", projectName),
 	}, &workspace)
 	if errors.IsNotFound(err) {
-		return "", "", fmt.Errorf("cant find workspace %v: %w", workspaceName, err)
+		return ChargingTargetResolution{}, fmt.Errorf("cant find workspace %v: %w", workspaceName, err)
 	} else if err != nil {
-		return "", "", fmt.Errorf("error when getting workspace %v: %w", workspaceName, err)
+		return ChargingTargetResolution{}, fmt.Errorf("error when getting workspace %v: %w", workspaceName, err)
 	}
 
 	err = client.Get(ctx, k8s.ObjectKey{
@@ -43,37 +158,136 @@ func ResolveChargingTarget(ctx context.Context, client k8s.Client, projectName s
 		Namespace: fmt.Sprintf("project-%s--ws-%s", projectName, workspaceName),
 	}, &mcp)
 	if errors.IsNotFound(err) {
-		return "", "", fmt.Errorf("cant find mcp %v: %w", mcpName, err)
+		return ChargingTargetResolution{}, fmt.Errorf("cant find mcp %v: %w", mcpName, err)
 	} else if err != nil {
-		return "", "", fmt.Errorf("error when getting mcp %v: %w", mcpName, err)
+		return ChargingTargetResolution{}, fmt.Errorf("error when getting mcp %v: %w", mcpName, err)
+	}
+
+	levels := map[string]k8s.Object{
+		config.LevelProject:   &project,
+		config.LevelWorkspace: &workspace,
+		config.LevelMCP:       &mcp,
 	}
 
 	foundOne := false
-	chargingTarget, ok := project.GetLabels()[labelChargingTarget]
-	chargingTargetType := project.GetLabels()[labelChargingTargetType]
-	if ok {
-		foundOne = true
+	chargingTarget, chargingTargetType := "", ""
+	for _, level := range r.config.Precedence {
+		obj, ok := levels[level]
+		if !ok {
+			continue
+		}
+
+		if levelChargingTarget, ok := r.lookup(obj, r.config.Key); ok {
+			foundOne = true
+			chargingTarget = levelChargingTarget
+			chargingTargetType, _ = r.lookup(obj, r.config.TypeKey)
+		}
+
+		if locked, _ := r.lookup(obj, r.config.LockKey); locked == "true" {
+			break
+		}
 	}
 
-	wsChargingTarget, ok := workspace.GetLabels()[labelChargingTarget]
-	wsChargingTargetType := workspace.GetLabels()[labelChargingTargetType]
-	if ok {
-		foundOne = true
-		chargingTarget = wsChargingTarget
-		chargingTargetType = wsChargingTargetType
+	if foundOne {
+		return ChargingTargetResolution{ChargingTarget: chargingTarget, ChargingTargetType: chargingTargetType}, nil
 	}
 
-	mcpChargingTarget, ok := mcp.GetLabels()[labelChargingTarget]
-	mcpChargingTargetType := mcp.GetLabels()[labelChargingTargetType]
-	if ok {
-		foundOne = true
-		chargingTarget = mcpChargingTarget
-		chargingTargetType = mcpChargingTargetType
+	resolution, err := evaluateRules(r.rules, &project, &workspace, &mcp)
+	if resolution.Rule != "" {
+		return resolution, nil
+	}
+	if err != nil {
+		return ChargingTargetResolution{}, fmt.Errorf("can't find any charging target for project(%s) workspace(%s) mcp(%s): %w", projectName, workspaceName, mcpName, err)
 	}
+	return ChargingTargetResolution{}, fmt.Errorf("can't find any charging target for project(%s) workspace(%s) mcp(%s)", projectName, workspaceName, mcpName)
+}
 
-	if !foundOne {
-		return "", "", fmt.Errorf("can't find any charging target for project(%s) workspace(%s) mcp(%s)", projectName, workspaceName, mcpName)
+// lookup returns the value of the key from the first configured source, which contains it.
+func (r *ChargingTargetResolver) lookup(obj k8s.Object, key string) (string, bool) {
+	for _, source := range r.config.Sources {
+		var values map[string]string
+		switch source {
+		case config.SourceLabels:
+			values = obj.GetLabels()
+		case config.SourceAnnotations:
+			values = obj.GetAnnotations()
+		}
+		if value, ok := values[key]; ok {
+			return value, true
+		}
 	}
+	return "", false
+}
+
+// Validates returns whether charging targets are validated against the ChargingTarget resources.
+func (r *ChargingTargetResolver) Validates() bool {
+	return r.config.Validate
+}
 
-	return chargingTarget, chargingTargetType, nil
+// Default returns the configured default charging target, which is used if no valid charging target was found.
+func (r *ChargingTargetResolver) Default() string {
+	return r.config.Default
+}
+
+// Validate checks the charging target against its ChargingTarget resource, if validation is enabled. It returns the
+// type of the charging target, which is taken from the ChargingTarget if the given type is empty. If the charging
+// target is not valid at the given time, an InvalidChargingTargetError is returned.
+func (r *ChargingTargetResolver) Validate(ctx context.Context, client k8s.Client, chargingTarget string, chargingTargetType string, at time.Time) (string, error) {
+	if !r.config.Validate {
+		return chargingTargetType, nil
+	}
+
+	var registered v2.ChargingTarget
+	err := client.Get(ctx, k8s.ObjectKey{Name: chargingTarget}, &registered)
+	if errors.IsNotFound(err) {
+		return chargingTargetType, &InvalidChargingTargetError{
+			ChargingTarget: chargingTarget,
+			Reason:         ReasonUnknown,
+			Message:        "no ChargingTarget is registered",
+		}
+	} else if err != nil {
+		return chargingTargetType, fmt.Errorf("error when getting charging target %v: %w", chargingTarget, err)
+	}
+
+	spec := registered.Spec
+	if spec.ValidUntil != nil && !at.Before(spec.ValidUntil.Time) {
+		return chargingTargetType, &InvalidChargingTargetError{
+			ChargingTarget: chargingTarget,
+			Reason:         ReasonExpired,
+			Message:        fmt.Sprintf("expired at %s", spec.ValidUntil.UTC().Format(time.RFC3339)),
+		}
+	}
+	if !spec.IsValidAt(at) {
+		return chargingTargetType, &InvalidChargingTargetError{
+			ChargingTarget: chargingTarget,
+			Reason:         ReasonNotYetValid,
+			Message:        fmt.Sprintf("valid from %s", spec.ValidFrom.UTC().Format(time.RFC3339)),
+		}
+	}
+	if chargingTargetType == "" {
+		return spec.Type, nil
+	}
+	if spec.Type != "" && spec.Type != chargingTargetType {
+		return chargingTargetType, &InvalidChargingTargetError{
+			ChargingTarget: chargingTarget,
+			Reason:         ReasonTypeMismatch,
+			Message:        fmt.Sprintf("type %q doesn't match the registered type %q", chargingTargetType, spec.Type),
+		}
+	}
+
+	return chargingTargetType, nil
+}
+
+// ValidateShares validates every share of a weighted charging target like Validate. The usage of a charging target is
+// reported with a single type, so the type of the first share is required for all further shares.
+func (r *ChargingTargetResolver) ValidateShares(ctx context.Context, client k8s.Client, chargingTarget string, chargingTargetType string, at time.Time) (string, error) {
+	validType := chargingTargetType
+	for _, share := range ChargingTargetShares(chargingTarget) {
+		shareType, err := r.Validate(ctx, client, share.ChargingTarget, validType, at)
+		if err != nil {
+			return chargingTargetType, err
+		}
+		validType = shareType
+	}
+	return validType, nil
 }
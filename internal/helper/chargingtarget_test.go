@@ -2,6 +2,8 @@ package helper
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -11,6 +13,9 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+	"github.com/openmcp-project/usage-operator/internal/config"
 )
 
 const (
@@ -51,8 +56,8 @@ var _ = Describe("Charging Target Resolver", Ordered, func() {
 			ObjectMeta: metav1.ObjectMeta{
 				Name: ProjectName,
 				Labels: map[string]string{
-					labelChargingTarget:     ChargingTarget,
-					labelChargingTargetType: ChargingTargetType,
+					config.DefaultChargingTargetKey:     ChargingTarget,
+					config.DefaultChargingTargetTypeKey: ChargingTargetType,
 				},
 			},
 		}
@@ -94,8 +99,8 @@ var _ = Describe("Charging Target Resolver", Ordered, func() {
 		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&workspace), &workspace)).Should(Succeed())
 
 		workspace.SetLabels(map[string]string{
-			labelChargingTarget:     "9876543",
-			labelChargingTargetType: "btp",
+			config.DefaultChargingTargetKey:     "9876543",
+			config.DefaultChargingTargetTypeKey: "btp",
 		})
 		Expect(k8sClient.Update(ctx, &workspace)).Should(Succeed())
 
@@ -118,8 +123,8 @@ var _ = Describe("Charging Target Resolver", Ordered, func() {
 		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&mcp), &mcp)).Should(Succeed())
 
 		mcp.SetLabels(map[string]string{
-			labelChargingTarget:     "14689283",
-			labelChargingTargetType: "btp",
+			config.DefaultChargingTargetKey:     "14689283",
+			config.DefaultChargingTargetTypeKey: "btp",
 		})
 		Expect(k8sClient.Update(ctx, &mcp)).Should(Succeed())
 
@@ -129,4 +134,204 @@ var _ = Describe("Charging Target Resolver", Ordered, func() {
 		Expect(resolvedChargingTarget).Should(Equal("14689283"))
 		Expect(resolvedChargingTargetType).Should(Equal("btp"))
 	})
+
+	It("Should not let the mcp override a locked workspace", func() {
+		ctx := context.Background()
+
+		workspace := pwcorev1alpha1.Workspace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      WorkspaceName,
+				Namespace: projectNamespaceName,
+			},
+		}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&workspace), &workspace)).Should(Succeed())
+
+		workspace.Labels[config.DefaultChargingTargetLockKey] = "true"
+		Expect(k8sClient.Update(ctx, &workspace)).Should(Succeed())
+
+		resolvedChargingTarget, _, err := ResolveChargingTarget(ctx, k8sClient, ProjectName, WorkspaceName, MCPName)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(resolvedChargingTarget).Should(Equal("9876543"))
+	})
+
+	It("Should resolve the charging target with configured keys, sources and precedence", func() {
+		ctx := context.Background()
+
+		project := pwcorev1alpha1.Project{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: ProjectName,
+			},
+		}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&project), &project)).Should(Succeed())
+		project.SetAnnotations(map[string]string{"example.com/cost-center": "1111"})
+		Expect(k8sClient.Update(ctx, &project)).Should(Succeed())
+
+		mcp := corev1alpha1.ManagedControlPlane{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      MCPName,
+				Namespace: workspaceNamespaceName,
+			},
+		}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&mcp), &mcp)).Should(Succeed())
+		mcp.SetAnnotations(map[string]string{"example.com/cost-center": "2222"})
+		Expect(k8sClient.Update(ctx, &mcp)).Should(Succeed())
+
+		cfg := config.New().ChargingTarget
+		cfg.Key = "example.com/cost-center"
+		cfg.Sources = []string{config.SourceAnnotations}
+		cfg.Precedence = []string{config.LevelMCP, config.LevelWorkspace, config.LevelProject}
+
+		resolver, err := NewChargingTargetResolver(cfg)
+		Expect(err).ShouldNot(HaveOccurred())
+		resolvedChargingTarget, resolvedChargingTargetType, err := resolver.Resolve(ctx, k8sClient, ProjectName, WorkspaceName, MCPName)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(resolvedChargingTarget).Should(Equal("1111"))
+		Expect(resolvedChargingTargetType).Should(BeEmpty())
+	})
+
+	It("Should derive the charging target from the first matching rule", func() {
+		ctx := context.Background()
+
+		cfg := config.New().ChargingTarget
+		cfg.Key = "example.com/unused"
+		cfg.Rules = []config.ChargingTargetRule{
+			{Name: "label", Expression: `project.labels["example.com/unused"]`},
+			{Name: "no-match", Expression: `mcp.name == "other" ? "cc-other" : ""`},
+			{Name: "project-name", Expression: `"cc-" + project.name.upperAscii()`, Type: "cost-center"},
+		}
+		resolver, err := NewChargingTargetResolver(cfg)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		resolution, err := resolver.Resolution(ctx, k8sClient, ProjectName, WorkspaceName, MCPName)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(resolution).Should(Equal(ChargingTargetResolution{
+			ChargingTarget:     "cc-PROJECT",
+			ChargingTargetType: "cost-center",
+			Rule:               "project-name",
+		}))
+	})
+
+	It("Should reject rules, which don't evaluate to a string", func() {
+		cfg := config.New().ChargingTarget
+		cfg.Rules = []config.ChargingTargetRule{{Name: "size", Expression: `size(project.labels)`}}
+
+		_, err := NewChargingTargetResolver(cfg)
+		Expect(err).Should(HaveOccurred())
+	})
+})
+
+var _ = Describe("Charging Target Validation", Ordered, func() {
+	var resolver *ChargingTargetResolver
+	now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+
+	BeforeAll(func() {
+		ctx := context.Background()
+
+		cfg := config.New().ChargingTarget
+		cfg.Validate = true
+		var err error
+		resolver, err = NewChargingTargetResolver(cfg)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		validUntil := metav1.NewTime(now.Add(-time.Hour))
+		for _, chargingTarget := range []v2.ChargingTarget{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "cc-valid"},
+				Spec:       v2.ChargingTargetSpec{Type: "cost-center"},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "cc-expired"},
+				Spec:       v2.ChargingTargetSpec{Type: "cost-center", ValidUntil: &validUntil},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "wbs-valid"},
+				Spec:       v2.ChargingTargetSpec{Type: "wbs"},
+			},
+		} {
+			Expect(k8sClient.Create(ctx, &chargingTarget)).To(Succeed())
+		}
+	})
+
+	It("should accept a registered charging target and take its type", func() {
+		chargingTargetType, err := resolver.Validate(context.Background(), k8sClient, "cc-valid", "", now)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(chargingTargetType).Should(Equal("cost-center"))
+	})
+
+	It("should reject unknown, expired and mismatching charging targets", func() {
+		for chargingTarget, reason := range map[string]string{
+			"cc-typo":    ReasonUnknown,
+			"cc-expired": ReasonExpired,
+		} {
+			_, err := resolver.Validate(context.Background(), k8sClient, chargingTarget, "", now)
+			var invalid *InvalidChargingTargetError
+			Expect(errors.As(err, &invalid)).Should(BeTrue())
+			Expect(invalid.Reason).Should(Equal(reason))
+		}
+
+		_, err := resolver.Validate(context.Background(), k8sClient, "cc-valid", "btp", now)
+		var invalid *InvalidChargingTargetError
+		Expect(errors.As(err, &invalid)).Should(BeTrue())
+		Expect(invalid.Reason).Should(Equal(ReasonTypeMismatch))
+	})
+
+	It("should require the same type for all shares of a weighted charging target", func() {
+		chargingTargetType, err := resolver.ValidateShares(context.Background(), k8sClient, "cc-valid", "", now)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(chargingTargetType).Should(Equal("cost-center"))
+
+		_, err = resolver.ValidateShares(context.Background(), k8sClient, "cc-valid:50,wbs-valid:50", "", now)
+		var invalid *InvalidChargingTargetError
+		Expect(errors.As(err, &invalid)).Should(BeTrue())
+		Expect(invalid.ChargingTarget).Should(Equal("wbs-valid"))
+		Expect(invalid.Reason).Should(Equal(ReasonTypeMismatch))
+	})
+
+	It("should reject a weighted charging target, once a share expired", func() {
+		_, err := resolver.ValidateShares(context.Background(), k8sClient, "cc-valid:50,cc-expired:50", "", now)
+		var invalid *InvalidChargingTargetError
+		Expect(errors.As(err, &invalid)).Should(BeTrue())
+		Expect(invalid.Reason).Should(Equal(ReasonExpired))
+	})
+
+	It("should not validate, if disabled", func() {
+		chargingTargetType, err := DefaultChargingTargetResolver().Validate(context.Background(), k8sClient, "cc-typo", "btp", now)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(chargingTargetType).Should(Equal("btp"))
+	})
+})
+
+var _ = Describe("Weighted Charging Target", func() {
+	It("should parse the shares of a weighted charging target", func() {
+		shares, err := ParseChargingTargetSplit("cc-1:70, cc-2:30")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(shares).Should(Equal([]v2.ChargingTargetShare{
+			{ChargingTarget: "cc-1", Weight: 70},
+			{ChargingTarget: "cc-2", Weight: 30},
+		}))
+	})
+
+	It("should return no shares for a single charging target", func() {
+		shares, err := ParseChargingTargetSplit("12345678")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(shares).Should(BeEmpty())
+		Expect(ChargingTargetShares("12345678")).Should(Equal([]v2.ChargingTargetShare{{ChargingTarget: "12345678", Weight: 100}}))
+	})
+
+	It("should reject invalid weighted charging targets", func() {
+		for _, value := range []string{
+			"cc-1:70,cc-2:20",
+			"cc-1:70,cc-1:30",
+			"cc-1:70,cc-2",
+			"cc-1:0,cc-2:100",
+			":50,cc-2:50",
+			"cc-1:seventy,cc-2:30",
+		} {
+			_, err := ParseChargingTargetSplit(value)
+			Expect(err).Should(HaveOccurred(), value)
+		}
+	})
 })
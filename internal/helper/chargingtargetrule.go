@@ -0,0 +1,116 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openmcp-project/usage-operator/internal/config"
+)
+
+// celCostLimit limits the evaluation cost of a single rule, so a rule can't block the reconciliation.
+const celCostLimit = 100000
+
+// chargingTargetRule is a compiled CEL rule, which derives a charging target from the metadata of project,
+// workspace and mcp.
+type chargingTargetRule struct {
+	name               string
+	chargingTargetType string
+	program            cel.Program
+}
+
+// compileChargingTargetRules compiles the given rules. Every rule must evaluate to a string.
+func compileChargingTargetRules(rules []config.ChargingTargetRule) ([]chargingTargetRule, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	metadataType := cel.MapType(cel.StringType, cel.DynType)
+	env, err := cel.NewEnv(
+		cel.Variable(config.LevelProject, metadataType),
+		cel.Variable(config.LevelWorkspace, metadataType),
+		cel.Variable(config.LevelMCP, metadataType),
+		ext.Strings(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cel environment: %w", err)
+	}
+
+	compiled := make([]chargingTargetRule, 0, len(rules))
+	for _, rule := range rules {
+		ast, issues := env.Compile(rule.Expression)
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("error compiling charging target rule %q: %w", rule.Name, issues.Err())
+		}
+		// the metadata is dynamically typed, so the type of most expressions is only known when they are evaluated
+		if outputType := ast.OutputType(); outputType != cel.StringType && outputType != cel.DynType {
+			return nil, fmt.Errorf("charging target rule %q must evaluate to a string, got %s", rule.Name, outputType)
+		}
+		program, err := env.Program(ast, cel.CostLimit(celCostLimit))
+		if err != nil {
+			return nil, fmt.Errorf("error creating program of charging target rule %q: %w", rule.Name, err)
+		}
+		compiled = append(compiled, chargingTargetRule{
+			name:               rule.Name,
+			chargingTargetType: rule.Type,
+			program:            program,
+		})
+	}
+
+	return compiled, nil
+}
+
+// metadataOf returns the metadata of the object, which is available to the rules.
+func metadataOf(obj k8s.Object) map[string]any {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	return map[string]any{
+		"name":        obj.GetName(),
+		"namespace":   obj.GetNamespace(),
+		"labels":      labels,
+		"annotations": annotations,
+	}
+}
+
+// evaluateRules returns the charging target of the first rule, which evaluates to a non-empty string. Rules which
+// fail to evaluate don't match, their errors are returned if no rule matched.
+func evaluateRules(rules []chargingTargetRule, project, workspace, mcp k8s.Object) (ChargingTargetResolution, error) {
+	vars := map[string]any{
+		config.LevelProject:   metadataOf(project),
+		config.LevelWorkspace: metadataOf(workspace),
+		config.LevelMCP:       metadataOf(mcp),
+	}
+
+	var errs error
+	for _, rule := range rules {
+		out, _, err := rule.program.Eval(vars)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("error evaluating charging target rule %q: %w", rule.name, err))
+			continue
+		}
+		chargingTarget, ok := out.Value().(string)
+		if !ok {
+			errs = errors.Join(errs, fmt.Errorf("charging target rule %q must evaluate to a string, got %s", rule.name, out.Type().TypeName()))
+			continue
+		}
+		if chargingTarget == "" {
+			continue
+		}
+		return ChargingTargetResolution{
+			ChargingTarget:     chargingTarget,
+			ChargingTargetType: rule.chargingTargetType,
+			Rule:               rule.name,
+		}, nil
+	}
+
+	return ChargingTargetResolution{}, errs
+}
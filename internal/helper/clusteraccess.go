@@ -19,6 +19,7 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 
 	"github.com/openmcp-project/usage-operator/api"
+	"github.com/openmcp-project/usage-operator/api/crds"
 
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -56,11 +57,21 @@ func GetOnboardingCluster(ctx context.Context, log logging.Logger, client client
 						},
 						Verbs: []string{"get", "list", "watch"},
 					},
+					{
+						APIGroups: []string{"core.openmcp.cloud"},
+						Resources: []string{"managedcontrolplanes", "managedcontrolplanes/finalizers"},
+						Verbs:     []string{"patch", "update"},
+					},
+					{
+						APIGroups: []string{"", "events.k8s.io"},
+						Resources: []string{"events"},
+						Verbs:     []string{"create", "patch", "update"},
+					},
 					{
 						APIGroups:     []string{"apiextensions.k8s.io"},
-						Resources:     []string{"customresourcedefinitions"},
+						Resources:     []string{"customresourcedefinitions", "customresourcedefinitions/status"},
 						Verbs:         []string{"get", "patch", "update", "delete"},
-						ResourceNames: []string{"mcpusages.usage.openmcp.cloud"},
+						ResourceNames: []string{crds.MCPUsageCRDName, crds.ChargingTargetCRDName, crds.MCPUsageMonthlyCRDName},
 					},
 					{
 						APIGroups: []string{"apiextensions.k8s.io"},
@@ -0,0 +1,68 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/openmcp-project/controller-utils/pkg/logging"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openmcp-project/usage-operator/api/crds"
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+)
+
+// MigrateStorageVersion rewrites all MCPUsages, so they are stored in the storage version of their CRD, and removes the
+// other versions from the stored versions of the CRD afterwards. Objects, which are stored in another version, are read
+// through the conversion webhook, so the migration is refused, if it isn't configured.
+func MigrateStorageVersion(ctx context.Context, log logging.Logger, c client.Client) error {
+	var crd apiextv1.CustomResourceDefinition
+	if err := c.Get(ctx, client.ObjectKey{Name: crds.MCPUsageCRDName}, &crd); err != nil {
+		return fmt.Errorf("error getting CRD %s: %w", crds.MCPUsageCRDName, err)
+	}
+	storage := crds.StorageVersion(&crd)
+	if storage == "" {
+		return fmt.Errorf("CRD %s has no storage version", crd.Name)
+	}
+	outdated := crds.OutdatedStoredVersions(&crd, &crd)
+	if len(outdated) == 0 {
+		log.Info("all MCPUsages are stored in the storage version", "version", storage)
+		return nil
+	}
+	if crd.Spec.Conversion == nil || crd.Spec.Conversion.Strategy != apiextv1.WebhookConverter {
+		return fmt.Errorf("MCPUsages are still stored as %v, which can't be read without the conversion webhook: run init with --conversion-webhook-url first", outdated)
+	}
+
+	var mcpUsages v2.MCPUsageList
+	if err := c.List(ctx, &mcpUsages); err != nil {
+		return fmt.Errorf("error listing MCPUsages: %w", err)
+	}
+	log.Info("migrating MCPUsages", "count", len(mcpUsages.Items), "from", outdated, "to", storage)
+	for i := range mcpUsages.Items {
+		// an update without changes writes the object in the storage version
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			var mcpUsage v2.MCPUsage
+			if err := c.Get(ctx, client.ObjectKeyFromObject(&mcpUsages.Items[i]), &mcpUsage); err != nil {
+				return client.IgnoreNotFound(err)
+			}
+			return c.Update(ctx, &mcpUsage)
+		})
+		if err != nil {
+			return fmt.Errorf("error migrating MCPUsage %s: %w", mcpUsages.Items[i].Name, err)
+		}
+	}
+
+	// objects, which were created in the meantime, are stored in the storage version already
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := c.Get(ctx, client.ObjectKeyFromObject(&crd), &crd); err != nil {
+			return err
+		}
+		crd.Status.StoredVersions = slices.DeleteFunc(crd.Status.StoredVersions, func(version string) bool { return version != storage })
+		if !slices.Contains(crd.Status.StoredVersions, storage) {
+			crd.Status.StoredVersions = append(crd.Status.StoredVersions, storage)
+		}
+		return c.Status().Update(ctx, &crd)
+	})
+}
@@ -0,0 +1,165 @@
+// Package metrics contains the Prometheus metrics of the usage-operator. They are registered with the registry of
+// controller-runtime, so they are served by the metrics server of the manager.
+package metrics
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+)
+
+const namespace = "usage_operator"
+
+var (
+	// MCPHours is the billable usage of MCPs in hours, which was captured since the start of the operator.
+	MCPHours = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mcp_hours_total",
+		Help:      "Billable MCP-hours captured, by project, workspace and charging target.",
+	}, []string{"project", "workspace", "charging_target"})
+
+	// MissingChargingTarget is the number of MCPUsages of existing MCPs, which have no charging target.
+	MissingChargingTarget = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "mcpusages_missing_charging_target",
+		Help:      "Number of MCPUsages of existing MCPs without a charging target.",
+	})
+
+	// ScheduledEventDuration is the duration of the scheduled usage capture.
+	ScheduledEventDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "scheduled_event_duration_seconds",
+		Help:      "Duration of the scheduled usage capture.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+	})
+
+	// ScheduledEventFailures is the number of scheduled usage captures, which failed for at least one MCPUsage.
+	ScheduledEventFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "scheduled_event_failures_total",
+		Help:      "Number of scheduled usage captures, which failed for at least one MCPUsage.",
+	})
+
+	// GarbageCollectedEntries is the number of DailyUsage entries, which were pruned by the garbage collection.
+	GarbageCollectedEntries = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "garbage_collected_entries_total",
+		Help:      "Number of DailyUsage entries pruned by the garbage collection.",
+	})
+
+	// UnreportedDaysRetained is the number of DailyUsage entries beyond the retention, which are kept, as the metering
+	// operator didn't acknowledge them yet.
+	UnreportedDaysRetained = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "unreported_days_retained",
+		Help:      "Number of DailyUsage entries beyond the retention, which are kept until the metering operator acknowledges them.",
+	})
+
+	// CloudEventsPending is the number of CloudEvents in the outbox, which were not delivered yet.
+	CloudEventsPending = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cloudevents_pending",
+		Help:      "Number of CloudEvents in the outbox, which were not delivered yet.",
+	})
+
+	// CloudEventsDelivered is the number of CloudEvents, which were accepted by the sink.
+	CloudEventsDelivered = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cloudevents_delivered_total",
+		Help:      "Number of CloudEvents accepted by the sink.",
+	})
+
+	// CloudEventsDeliveryFailures is the number of failed attempts to deliver a CloudEvent.
+	CloudEventsDeliveryFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cloudevents_delivery_failures_total",
+		Help:      "Number of failed attempts to deliver a CloudEvent to the sink.",
+	})
+
+	// CloudEventsDeadLettered is the number of CloudEvents, which the sink rejected and which were moved to the dead
+	// letters.
+	CloudEventsDeadLettered = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cloudevents_dead_lettered_total",
+		Help:      "Number of CloudEvents rejected by the sink and moved to the dead letters.",
+	})
+
+	// lastSuccessfulCapture is the unix time of the last scheduled usage capture without failures. Until the first
+	// capture it is the start of the capture, so a capture which never succeeds is noticed as well. It is zero, as long
+	// as this replica doesn't capture the usage.
+	lastSuccessfulCapture atomic.Int64
+
+	secondsSinceLastCapture = captureCollector{desc: prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "seconds_since_last_successful_capture"),
+		"Seconds since the last scheduled usage capture without failures.",
+		nil, nil,
+	)}
+)
+
+// captureCollector exports the seconds since the last successful capture. Only the leader captures the usage, so the
+// metric is only exported by the replica, which started the capture. Otherwise standby replicas would report a capture,
+// which never happens.
+type captureCollector struct {
+	desc *prometheus.Desc
+}
+
+func (c captureCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c captureCollector) Collect(ch chan<- prometheus.Metric) {
+	last := lastSuccessfulCapture.Load()
+	if last == 0 {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, time.Since(time.Unix(last, 0)).Seconds())
+}
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		MCPHours,
+		MissingChargingTarget,
+		ScheduledEventDuration,
+		ScheduledEventFailures,
+		GarbageCollectedEntries,
+		UnreportedDaysRetained,
+		CloudEventsPending,
+		CloudEventsDelivered,
+		CloudEventsDeliveryFailures,
+		CloudEventsDeadLettered,
+		secondsSinceLastCapture,
+	)
+}
+
+// RecordUsage adds the billable part of the captured usage to the MCP-hours of its charging targets. Usage, which is
+// not attributed to a charging target, is recorded with an empty charging target.
+func RecordUsage(project, workspace string, usages []v2.DailyUsage) {
+	for _, usage := range usages {
+		if len(usage.ChargingTargets) == 0 {
+			if usage.Usage.Duration > 0 {
+				MCPHours.WithLabelValues(project, workspace, "").Add(usage.Usage.Hours())
+			}
+			continue
+		}
+		for _, chargingTarget := range usage.ChargingTargets {
+			if chargingTarget.Usage.Duration > 0 {
+				MCPHours.WithLabelValues(project, workspace, chargingTarget.ChargingTarget).Add(chargingTarget.Usage.Hours())
+			}
+		}
+	}
+}
+
+// RecordCaptureStarted records, that this replica started to capture the usage at the given time. From then on, the
+// seconds since the last successful capture are exported.
+func RecordCaptureStarted(at time.Time) {
+	lastSuccessfulCapture.CompareAndSwap(0, at.Unix())
+}
+
+// RecordSuccessfulCapture records, that the scheduled usage capture succeeded at the given time.
+func RecordSuccessfulCapture(at time.Time) {
+	lastSuccessfulCapture.Store(at.Unix())
+}
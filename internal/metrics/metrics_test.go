@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+)
+
+var _ = Describe("Metrics", func() {
+	BeforeEach(func() {
+		MCPHours.Reset()
+	})
+
+	It("should record the billable hours per charging target", func() {
+		RecordUsage("project", "workspace", []v2.DailyUsage{
+			{
+				Usage:            metav1.Duration{Duration: 3 * time.Hour},
+				NonBillableUsage: metav1.Duration{Duration: time.Hour},
+				ChargingTargets: []v2.ChargingTargetUsage{
+					{ChargingTarget: "cc-1", Usage: metav1.Duration{Duration: 2 * time.Hour}},
+					{ChargingTarget: "cc-2", Usage: metav1.Duration{Duration: time.Hour}, NonBillableUsage: metav1.Duration{Duration: time.Hour}},
+				},
+			},
+			{
+				Usage: metav1.Duration{Duration: 30 * time.Minute},
+				ChargingTargets: []v2.ChargingTargetUsage{
+					{ChargingTarget: "cc-1", Usage: metav1.Duration{Duration: 30 * time.Minute}},
+				},
+			},
+		})
+
+		Expect(testutil.ToFloat64(MCPHours.WithLabelValues("project", "workspace", "cc-1"))).Should(Equal(2.5))
+		Expect(testutil.ToFloat64(MCPHours.WithLabelValues("project", "workspace", "cc-2"))).Should(Equal(1.0))
+	})
+
+	It("should record unattributed hours without charging target", func() {
+		RecordUsage("project", "workspace", []v2.DailyUsage{
+			{Usage: metav1.Duration{Duration: time.Hour}},
+			{NonBillableUsage: metav1.Duration{Duration: time.Hour}},
+		})
+
+		Expect(testutil.CollectAndCount(MCPHours)).Should(Equal(1))
+		Expect(testutil.ToFloat64(MCPHours.WithLabelValues("project", "workspace", ""))).Should(Equal(1.0))
+	})
+
+	It("should only report the seconds since the last successful capture, once the capture started", func() {
+		lastSuccessfulCapture.Store(0)
+		Expect(testutil.CollectAndCount(secondsSinceLastCapture)).Should(Equal(0))
+
+		RecordCaptureStarted(time.Now().Add(-time.Minute))
+		RecordCaptureStarted(time.Now())
+		Expect(testutil.ToFloat64(secondsSinceLastCapture)).Should(BeNumerically("~", time.Minute.Seconds(), 5))
+	})
+
+	It("should report the seconds since the last successful capture", func() {
+		RecordSuccessfulCapture(time.Now().Add(-time.Hour))
+
+		Expect(testutil.ToFloat64(secondsSinceLastCapture)).Should(BeNumerically("~", time.Hour.Seconds(), 5))
+	})
+})
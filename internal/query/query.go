@@ -0,0 +1,251 @@
+// Package query serves a read-only HTTP API, which answers questions about the usage of MCPs from the MCPUsages.
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+	"github.com/openmcp-project/usage-operator/internal/usage"
+)
+
+// PathPrefix is the path under which the API is served.
+const PathPrefix = "/usage/"
+
+const (
+	dateFormat  = time.DateOnly
+	monthFormat = "2006-01"
+)
+
+// DailyUsage is the usage of a day in hours.
+type DailyUsage struct {
+	Date                  string  `json:"date"`
+	UsageHours            float64 `json:"usage_hours"`
+	NonBillableUsageHours float64 `json:"non_billable_usage_hours"`
+}
+
+// MCPUsage is the usage of an MCP within the requested date range.
+type MCPUsage struct {
+	Project   string `json:"project"`
+	Workspace string `json:"workspace"`
+	MCP       string `json:"mcp"`
+	// MCPUsage is the name of the MCPUsage, which distinguishes incarnations of an MCP with the same name.
+	MCPUsage              string       `json:"mcp_usage"`
+	ChargingTarget        string       `json:"charging_target"`
+	UsageHours            float64      `json:"usage_hours"`
+	NonBillableUsageHours float64      `json:"non_billable_usage_hours"`
+	Days                  []DailyUsage `json:"days"`
+}
+
+// MCPsResponse is the response of the mcps endpoint.
+type MCPsResponse struct {
+	From string     `json:"from"`
+	To   string     `json:"to"`
+	MCPs []MCPUsage `json:"mcps"`
+}
+
+// ChargingTargetResponse is the response of the charging target endpoint. The usage of the mcps is their share, which
+// is charged to the charging target.
+type ChargingTargetResponse struct {
+	ChargingTarget        string     `json:"charging_target"`
+	From                  string     `json:"from"`
+	To                    string     `json:"to"`
+	UsageHours            float64    `json:"usage_hours"`
+	NonBillableUsageHours float64    `json:"non_billable_usage_hours"`
+	MCPs                  []MCPUsage `json:"mcps"`
+}
+
+// Handler serves the usage API from the MCPUsages of the reader, which is usually the cache of the manager.
+type Handler struct {
+	reader          client.Reader
+	billingLocation *time.Location
+	log             logr.Logger
+	mux             *http.ServeMux
+}
+
+// NewHandler returns the handler of the usage API. The billing location determines the days of MCPUsages without a
+// billing timezone of their own.
+func NewHandler(reader client.Reader, billingLocation *time.Location, log logr.Logger) *Handler {
+	h := &Handler{
+		reader:          reader,
+		billingLocation: billingLocation,
+		log:             log,
+		mux:             http.NewServeMux(),
+	}
+	h.mux.HandleFunc("GET "+PathPrefix+"v1/mcps", h.mcps)
+	h.mux.HandleFunc("GET "+PathPrefix+"v1/charging-targets/{chargingTarget}", h.chargingTarget)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h.mux.ServeHTTP(w, req)
+}
+
+// mcps returns the usage per day of all mcps, which match the project, workspace and charging_target parameters.
+func (h *Handler) mcps(w http.ResponseWriter, req *http.Request) {
+	from, to, err := dateRange(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	params := req.URL.Query()
+
+	mcpUsages, err := h.list(req)
+	if err != nil {
+		h.log.Error(err, "error listing MCPUsages")
+		http.Error(w, "error listing MCPUsages", http.StatusInternalServerError)
+		return
+	}
+	mcpUsages = slices.DeleteFunc(mcpUsages, func(mcpUsage v2.MCPUsage) bool {
+		return (params.Has("project") && mcpUsage.Spec.Project != params.Get("project")) ||
+			(params.Has("workspace") && mcpUsage.Spec.Workspace != params.Get("workspace"))
+	})
+
+	h.respond(w, MCPsResponse{
+		From: from,
+		To:   to,
+		MCPs: h.collect(mcpUsages, from, to, params.Get("charging_target")),
+	})
+}
+
+// chargingTarget returns the usage, which is charged to the charging target, in total and per mcp.
+func (h *Handler) chargingTarget(w http.ResponseWriter, req *http.Request) {
+	from, to, err := dateRange(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	mcpUsages, err := h.list(req)
+	if err != nil {
+		h.log.Error(err, "error listing MCPUsages")
+		http.Error(w, "error listing MCPUsages", http.StatusInternalServerError)
+		return
+	}
+
+	response := ChargingTargetResponse{
+		ChargingTarget: req.PathValue("chargingTarget"),
+		From:           from,
+		To:             to,
+		MCPs:           h.collect(mcpUsages, from, to, req.PathValue("chargingTarget")),
+	}
+	for _, mcp := range response.MCPs {
+		response.UsageHours += mcp.UsageHours
+		response.NonBillableUsageHours += mcp.NonBillableUsageHours
+	}
+	h.respond(w, response)
+}
+
+func (h *Handler) list(req *http.Request) ([]v2.MCPUsage, error) {
+	var mcpUsages v2.MCPUsageList
+	if err := h.reader.List(req.Context(), &mcpUsages); err != nil {
+		return nil, err
+	}
+	return mcpUsages.Items, nil
+}
+
+// collect returns the usage per day of the mcps within the date range. If a charging target is given, only the usage
+// charged to it is returned, and mcps without such usage are left out.
+func (h *Handler) collect(mcpUsages []v2.MCPUsage, from, to, chargingTarget string) []MCPUsage {
+	result := []MCPUsage{}
+	for i := range mcpUsages {
+		mcpUsage := &mcpUsages[i]
+		var usageSum, nonBillableSum time.Duration
+		days := []DailyUsage{}
+		for _, day := range mcpUsage.Status.UsageOperator.Usage {
+			date := usage.BillingDate(mcpUsage, day, h.billingLocation)
+			if date < from || date > to {
+				continue
+			}
+
+			billable, nonBillable := day.Usage.Duration, day.NonBillableUsage.Duration
+			if chargingTarget != "" {
+				billable, nonBillable = 0, 0
+				for _, share := range usage.ChargingTargetUsages(mcpUsage, day) {
+					if share.ChargingTarget == chargingTarget {
+						billable += share.Usage.Duration
+						nonBillable += share.NonBillableUsage.Duration
+					}
+				}
+				if billable == 0 && nonBillable == 0 {
+					continue
+				}
+			}
+
+			usageSum += billable
+			nonBillableSum += nonBillable
+			days = append(days, DailyUsage{Date: date, UsageHours: billable.Hours(), NonBillableUsageHours: nonBillable.Hours()})
+		}
+		if chargingTarget != "" && len(days) == 0 {
+			continue
+		}
+
+		slices.SortFunc(days, func(a, b DailyUsage) int { return strings.Compare(a.Date, b.Date) })
+		result = append(result, MCPUsage{
+			Project:               mcpUsage.Spec.Project,
+			Workspace:             mcpUsage.Spec.Workspace,
+			MCP:                   mcpUsage.Spec.MCP,
+			MCPUsage:              mcpUsage.Name,
+			ChargingTarget:        mcpUsage.Status.UsageOperator.ChargingTarget,
+			UsageHours:            usageSum.Hours(),
+			NonBillableUsageHours: nonBillableSum.Hours(),
+			Days:                  days,
+		})
+	}
+
+	slices.SortFunc(result, func(a, b MCPUsage) int {
+		return strings.Compare(
+			strings.Join([]string{a.Project, a.Workspace, a.MCP, a.MCPUsage}, "/"),
+			strings.Join([]string{b.Project, b.Workspace, b.MCP, b.MCPUsage}, "/"),
+		)
+	})
+	return result
+}
+
+func (h *Handler) respond(w http.ResponseWriter, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.log.Error(err, "error writing response")
+	}
+}
+
+// dateRange returns the first and last day of the request. They are either given by the month parameter, or by the
+// from and to parameters. The default is the current month.
+func dateRange(req *http.Request) (string, string, error) {
+	params := req.URL.Query()
+	if params.Has("month") && (params.Has("from") || params.Has("to")) {
+		return "", "", fmt.Errorf("month can't be combined with from or to")
+	}
+
+	month := time.Now().UTC().Format(monthFormat)
+	if params.Has("month") {
+		month = params.Get("month")
+	}
+	start, err := time.Parse(monthFormat, month)
+	if err != nil {
+		return "", "", fmt.Errorf("month %q must be formatted as YYYY-MM", month)
+	}
+	from := start.Format(dateFormat)
+	to := start.AddDate(0, 1, -1).Format(dateFormat)
+
+	for name, value := range map[string]*string{"from": &from, "to": &to} {
+		if !params.Has(name) {
+			continue
+		}
+		if _, err := time.Parse(dateFormat, params.Get(name)); err != nil {
+			return "", "", fmt.Errorf("%s %q must be formatted as YYYY-MM-DD", name, params.Get(name))
+		}
+		*value = params.Get(name)
+	}
+	if to < from {
+		return "", "", fmt.Errorf("to %s is before from %s", to, from)
+	}
+	return from, to, nil
+}
@@ -0,0 +1,123 @@
+package query
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+)
+
+func dailyUsage(d int, usage time.Duration) v2.DailyUsage {
+	return v2.DailyUsage{
+		Date:  metav1.NewTime(time.Date(2025, 7, d, 0, 0, 0, 0, time.UTC)),
+		Usage: metav1.Duration{Duration: usage},
+	}
+}
+
+var _ = Describe("Query", func() {
+	var handler *Handler
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(v2.AddToScheme(scheme)).To(Succeed())
+		reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&v2.MCPUsage{
+				ObjectMeta: metav1.ObjectMeta{Name: "usage-1"},
+				Spec:       v2.MCPUsageSpec{Project: "project-a", Workspace: "workspace", MCP: "mcp-1"},
+				Status: v2.MCPUsageStatus{UsageOperator: v2.UsageOperatorStatus{
+					ChargingTarget: "cc-1:50,cc-2:50",
+					Usage:          []v2.DailyUsage{dailyUsage(2, 24*time.Hour), dailyUsage(1, 10*time.Hour)},
+				}},
+			},
+			&v2.MCPUsage{
+				ObjectMeta: metav1.ObjectMeta{Name: "usage-2"},
+				Spec:       v2.MCPUsageSpec{Project: "project-a", Workspace: "workspace", MCP: "mcp-2"},
+				Status: v2.MCPUsageStatus{UsageOperator: v2.UsageOperatorStatus{
+					ChargingTarget: "cc-1",
+					Usage:          []v2.DailyUsage{dailyUsage(1, 8*time.Hour)},
+				}},
+			},
+			&v2.MCPUsage{
+				ObjectMeta: metav1.ObjectMeta{Name: "usage-3"},
+				Spec:       v2.MCPUsageSpec{Project: "project-b", Workspace: "workspace", MCP: "mcp-3"},
+				Status: v2.MCPUsageStatus{UsageOperator: v2.UsageOperatorStatus{
+					ChargingTarget: "cc-3",
+					Usage:          []v2.DailyUsage{dailyUsage(1, 4*time.Hour), {Date: metav1.NewTime(time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)), Usage: metav1.Duration{Duration: time.Hour}}},
+				}},
+			},
+		).Build()
+		handler = NewHandler(reader, time.UTC, logr.Discard())
+	})
+
+	get := func(target string, response any) int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		if recorder.Code == http.StatusOK {
+			Expect(json.Unmarshal(recorder.Body.Bytes(), response)).To(Succeed())
+		}
+		return recorder.Code
+	}
+
+	It("should return the mcps of a project with their hours per day", func() {
+		var response MCPsResponse
+		Expect(get("/usage/v1/mcps?project=project-a&month=2025-07", &response)).Should(Equal(http.StatusOK))
+
+		Expect(response).Should(Equal(MCPsResponse{
+			From: "2025-07-01",
+			To:   "2025-07-31",
+			MCPs: []MCPUsage{
+				{
+					Project: "project-a", Workspace: "workspace", MCP: "mcp-1", MCPUsage: "usage-1", ChargingTarget: "cc-1:50,cc-2:50",
+					UsageHours: 34,
+					Days:       []DailyUsage{{Date: "2025-07-01", UsageHours: 10}, {Date: "2025-07-02", UsageHours: 24}},
+				},
+				{
+					Project: "project-a", Workspace: "workspace", MCP: "mcp-2", MCPUsage: "usage-2", ChargingTarget: "cc-1",
+					UsageHours: 8,
+					Days:       []DailyUsage{{Date: "2025-07-01", UsageHours: 8}},
+				},
+			},
+		}))
+	})
+
+	It("should only return the days within the date range", func() {
+		var response MCPsResponse
+		Expect(get("/usage/v1/mcps?project=project-b&from=2025-07-15&to=2025-08-15", &response)).Should(Equal(http.StatusOK))
+
+		Expect(response.MCPs).Should(HaveLen(1))
+		Expect(response.MCPs[0].Days).Should(Equal([]DailyUsage{{Date: "2025-08-01", UsageHours: 1}}))
+	})
+
+	It("should return the usage charged to a charging target in a month", func() {
+		var response ChargingTargetResponse
+		Expect(get("/usage/v1/charging-targets/cc-1?month=2025-07", &response)).Should(Equal(http.StatusOK))
+
+		Expect(response.ChargingTarget).Should(Equal("cc-1"))
+		Expect(response.UsageHours).Should(Equal(25.0))
+		Expect(response.MCPs).Should(HaveLen(2))
+		Expect(response.MCPs[0].MCP).Should(Equal("mcp-1"))
+		Expect(response.MCPs[0].Days).Should(Equal([]DailyUsage{{Date: "2025-07-01", UsageHours: 5}, {Date: "2025-07-02", UsageHours: 12}}))
+		Expect(response.MCPs[1].UsageHours).Should(Equal(8.0))
+	})
+
+	It("should reject invalid date parameters", func() {
+		Expect(get("/usage/v1/mcps?month=July", nil)).Should(Equal(http.StatusBadRequest))
+		Expect(get("/usage/v1/mcps?from=2025-07-02&to=2025-07-01", nil)).Should(Equal(http.StatusBadRequest))
+		Expect(get("/usage/v1/mcps?month=2025-07&from=2025-07-01", nil)).Should(Equal(http.StatusBadRequest))
+	})
+
+	It("should only answer read requests", func() {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/usage/v1/mcps", nil))
+		Expect(recorder.Code).Should(Equal(http.StatusMethodNotAllowed))
+	})
+})
@@ -0,0 +1,14 @@
+package query
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestQuery(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Query Suite")
+}
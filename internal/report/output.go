@@ -0,0 +1,114 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	FormatTable = "table"
+	FormatCSV   = "csv"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+)
+
+// Formats are the supported output formats of a report.
+var Formats = []string{FormatTable, FormatCSV, FormatJSON, FormatYAML}
+
+// column is a column of the tabular output formats.
+type column struct {
+	header string
+	name   string
+	value  func(Total) string
+}
+
+func hours(h float64) string {
+	return strconv.FormatFloat(h, 'f', 2, 64)
+}
+
+// columns returns the columns of the grouping, the names match the json fields.
+func columns(groupBy string) []column {
+	var cols []column
+	switch groupBy {
+	case GroupByProject:
+		cols = append(cols, column{"PROJECT", "project", func(t Total) string { return t.Project }})
+	case GroupByWorkspace:
+		cols = append(cols,
+			column{"PROJECT", "project", func(t Total) string { return t.Project }},
+			column{"WORKSPACE", "workspace", func(t Total) string { return t.Workspace }},
+		)
+	case GroupByChargingTarget:
+		cols = append(cols,
+			column{"CHARGING TARGET", "charging_target", func(t Total) string { return t.ChargingTarget }},
+			column{"TYPE", "charging_target_type", func(t Total) string { return t.ChargingTargetType }},
+		)
+	}
+	return append(cols,
+		column{"MCPS", "mcps", func(t Total) string { return strconv.Itoa(t.MCPs) }},
+		column{"USAGE (H)", "usage_hours", func(t Total) string { return hours(t.UsageHours) }},
+		column{"NON-BILLABLE (H)", "non_billable_usage_hours", func(t Total) string { return hours(t.NonBillableUsageHours) }},
+	)
+}
+
+// Write writes the report in the given format. Table and csv contain only the totals, json and yaml contain the
+// whole report.
+func Write(w io.Writer, report UsageReport, format string) error {
+	cols := columns(report.GroupBy)
+	row := func(t Total) []string {
+		values := make([]string, 0, len(cols))
+		for _, col := range cols {
+			values = append(values, col.value(t))
+		}
+		return values
+	}
+
+	switch format {
+	case FormatTable:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		headers := make([]string, 0, len(cols))
+		for _, col := range cols {
+			headers = append(headers, col.header)
+		}
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+		for _, t := range report.Totals {
+			fmt.Fprintln(tw, strings.Join(row(t), "\t"))
+		}
+		return tw.Flush()
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		headers := make([]string, 0, len(cols))
+		for _, col := range cols {
+			headers = append(headers, col.name)
+		}
+		if err := cw.Write(headers); err != nil {
+			return err
+		}
+		for _, t := range report.Totals {
+			if err := cw.Write(row(t)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case FormatYAML:
+		data, err := yaml.Marshal(report)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	default:
+		return fmt.Errorf("unsupported output format %q, must be one of %s", format, strings.Join(Formats, ", "))
+	}
+}
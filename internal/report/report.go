@@ -0,0 +1,131 @@
+// Package report aggregates the daily usage of MCPUsages over a date range.
+package report
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+	"github.com/openmcp-project/usage-operator/internal/usage"
+)
+
+const (
+	GroupByProject        = "project"
+	GroupByWorkspace      = "workspace"
+	GroupByChargingTarget = "charging-target"
+)
+
+// GroupBys are the supported groupings of the usage.
+var GroupBys = []string{GroupByProject, GroupByWorkspace, GroupByChargingTarget}
+
+// DateFormat is the format of the dates of a report.
+const DateFormat = time.DateOnly
+
+// Options select the usage, which is aggregated in a report.
+type Options struct {
+	// From is the first day of the report.
+	From time.Time
+	// To is the last day of the report.
+	To time.Time
+	// GroupBy determines the totals of the report, see GroupBys.
+	GroupBy string
+	// BillingLocation determines the days of MCPUsages without a billing timezone of their own.
+	BillingLocation *time.Location
+}
+
+// UsageReport contains the usage totals of a date range.
+type UsageReport struct {
+	From    string  `json:"from"`
+	To      string  `json:"to"`
+	GroupBy string  `json:"group_by"`
+	Totals  []Total `json:"totals"`
+}
+
+// Total is the usage of a project, workspace or charging target within the date range of the report. Only the fields
+// of the grouping are set.
+type Total struct {
+	Project            string `json:"project,omitempty"`
+	Workspace          string `json:"workspace,omitempty"`
+	ChargingTarget     string `json:"charging_target,omitempty"`
+	ChargingTargetType string `json:"charging_target_type,omitempty"`
+	// MCPs is the number of MCPUsages, which contributed to the total.
+	MCPs                  int     `json:"mcps"`
+	UsageHours            float64 `json:"usage_hours"`
+	NonBillableUsageHours float64 `json:"non_billable_usage_hours"`
+}
+
+// total accumulates the usage of a group exactly, before it is converted to hours.
+type total struct {
+	Total
+	usage       time.Duration
+	nonBillable time.Duration
+	mcpUsages   map[string]struct{}
+}
+
+func (t *total) add(mcpUsage string, usage, nonBillable time.Duration) {
+	t.usage += usage
+	t.nonBillable += nonBillable
+	t.mcpUsages[mcpUsage] = struct{}{}
+}
+
+// Aggregate sums up the daily usage of the MCPUsages within the date range of the options. Usage grouped by charging
+// target is split by the charging targets, which were active on the respective day.
+func Aggregate(mcpUsages []v2.MCPUsage, opts Options) (UsageReport, error) {
+	if !slices.Contains(GroupBys, opts.GroupBy) {
+		return UsageReport{}, fmt.Errorf("unsupported grouping %q, must be one of %s", opts.GroupBy, strings.Join(GroupBys, ", "))
+	}
+	loc := opts.BillingLocation
+	if loc == nil {
+		loc = time.UTC
+	}
+	from, to := opts.From.Format(DateFormat), opts.To.Format(DateFormat)
+
+	totals := map[Total]*total{}
+	group := func(key Total) *total {
+		if t, ok := totals[key]; ok {
+			return t
+		}
+		t := &total{Total: key, mcpUsages: map[string]struct{}{}}
+		totals[key] = t
+		return t
+	}
+
+	for i := range mcpUsages {
+		mcpUsage := &mcpUsages[i]
+		for _, day := range mcpUsage.Status.UsageOperator.Usage {
+			if date := usage.BillingDate(mcpUsage, day, loc); date < from || date > to {
+				continue
+			}
+
+			switch opts.GroupBy {
+			case GroupByProject:
+				group(Total{Project: mcpUsage.Spec.Project}).add(mcpUsage.Name, day.Usage.Duration, day.NonBillableUsage.Duration)
+			case GroupByWorkspace:
+				group(Total{Project: mcpUsage.Spec.Project, Workspace: mcpUsage.Spec.Workspace}).add(mcpUsage.Name, day.Usage.Duration, day.NonBillableUsage.Duration)
+			case GroupByChargingTarget:
+				for _, chargingTarget := range usage.ChargingTargetUsages(mcpUsage, day) {
+					group(Total{ChargingTarget: chargingTarget.ChargingTarget, ChargingTargetType: chargingTarget.ChargingTargetType}).
+						add(mcpUsage.Name, chargingTarget.Usage.Duration, chargingTarget.NonBillableUsage.Duration)
+				}
+			}
+		}
+	}
+
+	report := UsageReport{From: from, To: to, GroupBy: opts.GroupBy, Totals: make([]Total, 0, len(totals))}
+	for _, t := range totals {
+		t.MCPs = len(t.mcpUsages)
+		t.UsageHours = t.usage.Hours()
+		t.NonBillableUsageHours = t.nonBillable.Hours()
+		report.Totals = append(report.Totals, t.Total)
+	}
+	slices.SortFunc(report.Totals, func(a, b Total) int {
+		return strings.Compare(
+			strings.Join([]string{a.Project, a.Workspace, a.ChargingTarget, a.ChargingTargetType}, "/"),
+			strings.Join([]string{b.Project, b.Workspace, b.ChargingTarget, b.ChargingTargetType}, "/"),
+		)
+	})
+
+	return report, nil
+}
@@ -0,0 +1,113 @@
+package report
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+)
+
+func day(d int, usage time.Duration, chargingTargets ...v2.ChargingTargetUsage) v2.DailyUsage {
+	return v2.DailyUsage{
+		Date:            metav1.NewTime(time.Date(2025, 7, d, 0, 0, 0, 0, time.UTC)),
+		Usage:           metav1.Duration{Duration: usage},
+		ChargingTargets: chargingTargets,
+	}
+}
+
+func mcpUsage(name, project, workspace, chargingTarget string, usages ...v2.DailyUsage) v2.MCPUsage {
+	return v2.MCPUsage{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v2.MCPUsageSpec{Project: project, Workspace: workspace, MCP: name},
+		Status: v2.MCPUsageStatus{
+			UsageOperator: v2.UsageOperatorStatus{ChargingTarget: chargingTarget, Usage: usages},
+		},
+	}
+}
+
+var _ = Describe("Report", func() {
+	mcpUsages := []v2.MCPUsage{
+		mcpUsage("mcp-1", "project-a", "dev", "cc-1",
+			day(1, 24*time.Hour),
+			day(2, 24*time.Hour,
+				v2.ChargingTargetUsage{ChargingTarget: "cc-1", Usage: metav1.Duration{Duration: 12 * time.Hour}},
+				v2.ChargingTargetUsage{ChargingTarget: "cc-2", Usage: metav1.Duration{Duration: 12 * time.Hour}},
+			),
+			day(3, 24*time.Hour),
+		),
+		mcpUsage("mcp-2", "project-a", "prod", "cc-2", day(2, 6*time.Hour)),
+		mcpUsage("mcp-3", "project-b", "dev", "cc-2", day(2, 3*time.Hour)),
+	}
+	opts := Options{
+		From: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC),
+	}
+
+	It("should sum up the usage per project within the date range", func() {
+		opts.GroupBy = GroupByProject
+		report, err := Aggregate(mcpUsages, opts)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(report.Totals).Should(Equal([]Total{
+			{Project: "project-a", MCPs: 2, UsageHours: 54},
+			{Project: "project-b", MCPs: 1, UsageHours: 3},
+		}))
+	})
+
+	It("should sum up the usage per workspace", func() {
+		opts.GroupBy = GroupByWorkspace
+		report, err := Aggregate(mcpUsages, opts)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(report.Totals).Should(Equal([]Total{
+			{Project: "project-a", Workspace: "dev", MCPs: 1, UsageHours: 48},
+			{Project: "project-a", Workspace: "prod", MCPs: 1, UsageHours: 6},
+			{Project: "project-b", Workspace: "dev", MCPs: 1, UsageHours: 3},
+		}))
+	})
+
+	It("should split the usage by the charging targets of each day", func() {
+		opts.GroupBy = GroupByChargingTarget
+		report, err := Aggregate(mcpUsages, opts)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(report.Totals).Should(Equal([]Total{
+			{ChargingTarget: "cc-1", MCPs: 1, UsageHours: 36},
+			{ChargingTarget: "cc-2", MCPs: 3, UsageHours: 21},
+		}))
+	})
+
+	It("should reject an unknown grouping", func() {
+		opts.GroupBy = "mcp"
+		_, err := Aggregate(mcpUsages, opts)
+		Expect(err).Should(HaveOccurred())
+	})
+
+	It("should write the totals in every format", func() {
+		opts.GroupBy = GroupByProject
+		report, err := Aggregate(mcpUsages, opts)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		var out bytes.Buffer
+		Expect(Write(&out, report, FormatCSV)).Should(Succeed())
+		Expect(out.String()).Should(Equal("project,mcps,usage_hours,non_billable_usage_hours\nproject-a,2,54.00,0.00\nproject-b,1,3.00,0.00\n"))
+
+		out.Reset()
+		Expect(Write(&out, report, FormatTable)).Should(Succeed())
+		Expect(out.String()).Should(HavePrefix("PROJECT    MCPS  USAGE (H)  NON-BILLABLE (H)\nproject-a  2     54.00"))
+
+		out.Reset()
+		Expect(Write(&out, report, FormatJSON)).Should(Succeed())
+		Expect(out.String()).Should(ContainSubstring(`"usage_hours": 54`))
+
+		out.Reset()
+		Expect(Write(&out, report, FormatYAML)).Should(Succeed())
+		Expect(out.String()).Should(ContainSubstring("group_by: project"))
+
+		Expect(Write(&out, report, "xml")).ShouldNot(Succeed())
+	})
+})
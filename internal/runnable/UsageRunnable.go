@@ -6,31 +6,77 @@ import (
 	"fmt"
 	"time"
 
+	corev1alpha1 "github.com/openmcp-project/mcp-operator/api/core/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/events"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+	"github.com/openmcp-project/usage-operator/internal/cloudevents"
+	"github.com/openmcp-project/usage-operator/internal/export"
+	"github.com/openmcp-project/usage-operator/internal/metrics"
 	"github.com/openmcp-project/usage-operator/internal/usage"
 )
 
 const interval = 60 * time.Minute
 
+// orphanBackoff is the backoff of the orphan reconciliation on startup. It is retried for about 15 seconds.
+var orphanBackoff = wait.Backoff{Duration: time.Second, Factor: 2, Steps: 5}
+
 type UsageRunnable struct {
 	client       client.Client
 	usageTracker *usage.UsageTracker
+	recorder     events.EventRecorder
+
+	publisher        *cloudevents.Publisher
+	closedDaysCursor string
+	billingLocation  *time.Location
 }
 
-func NewUsageRunnable(client client.Client, usageTracker *usage.UsageTracker) UsageRunnable {
+func NewUsageRunnable(client client.Client, usageTracker *usage.UsageTracker, recorder events.EventRecorder) UsageRunnable {
 	return UsageRunnable{
 		client:       client,
 		usageTracker: usageTracker,
+		recorder:     recorder,
 	}
 }
 
+// WithPublisher publishes a CloudEvent for every day, whose usage is final. The days, which were published already, are
+// remembered in the cursor file. The billing location determines the days of MCPUsages without a billing timezone of
+// their own.
+func (u *UsageRunnable) WithPublisher(publisher *cloudevents.Publisher, cursorFile string, billingLocation *time.Location) *UsageRunnable {
+	u.publisher = publisher
+	u.closedDaysCursor = cursorFile
+	u.billingLocation = billingLocation
+	return u
+}
+
 func (u *UsageRunnable) NeedLeaderElection() bool {
 	return true
 }
 
 func (u *UsageRunnable) Start(ctx context.Context) error {
-	err := u.loop(ctx)
+	// only the leader captures the usage, so only it reports the time since the last capture
+	metrics.RecordCaptureStarted(time.Now())
+
+	// orphans have to be marked as deleted before the first scheduled event, otherwise they are billed until now. As
+	// the MCPUsages, which were marked already, are skipped, the reconciliation is retried as a whole. If it still
+	// fails, the usage is captured anyway, as failing here would only restart the operator.
+	err := wait.ExponentialBackoffWithContext(ctx, orphanBackoff, func(ctx context.Context) (bool, error) {
+		if err := u.reconcileOrphans(ctx); err != nil {
+			logf.FromContext(ctx).Error(err, "error in orphan reconciliation, retrying")
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "orphan reconciliation failed, continuing without it")
+	}
+
+	err = u.loop(ctx)
 	if err != nil {
 		return err
 	}
@@ -56,6 +102,14 @@ func (u *UsageRunnable) loop(ctx context.Context) (errs error) {
 		errs = errors.Join(errs, fmt.Errorf("error in scheduled event: %w", err))
 	}
 
+	// the days are published before the garbage collection, so no day is pruned before it was published. Errors are
+	// only logged, as the days are published again with the next run.
+	if u.publisher != nil {
+		if err := u.publishClosedDays(ctx); err != nil {
+			logf.FromContext(ctx).Error(err, "error publishing closed days")
+		}
+	}
+
 	err = u.usageTracker.GarbageCollection(ctx)
 	if err != nil {
 		errs = errors.Join(errs, fmt.Errorf("error in garbage collection: %w", err))
@@ -63,3 +117,132 @@ func (u *UsageRunnable) loop(ctx context.Context) (errs error) {
 
 	return
 }
+
+// reconcileOrphans marks every MCPUsage as deleted, whose mcp was deleted while the usage-operator was not running.
+// The deletion time is the deletion timestamp of the mcp, if it still exists, or the last capture of the usage.
+func (u *UsageRunnable) reconcileOrphans(ctx context.Context) (errs error) {
+	log := logf.FromContext(ctx).WithName("orphans")
+
+	var mcpUsages v2.MCPUsageList
+	if err := u.client.List(ctx, &mcpUsages); err != nil {
+		return fmt.Errorf("error listing MCPUsages: %w", err)
+	}
+
+	for i := range mcpUsages.Items {
+		mcpUsage := &mcpUsages.Items[i]
+		if !mcpUsage.Status.UsageOperator.MCPDeletedAt.IsZero() {
+			continue
+		}
+
+		var mcp corev1alpha1.ManagedControlPlane
+		err := u.client.Get(ctx, client.ObjectKey{
+			Namespace: usage.GetNamespacedName(mcpUsage.Spec.Project, mcpUsage.Spec.Workspace),
+			Name:      mcpUsage.Spec.MCP,
+		}, &mcp)
+
+		var deletedAt time.Time
+		var message string
+		switch {
+		case apierrors.IsNotFound(err):
+			deletedAt = lastSeen(mcpUsage)
+			message = "mcp was deleted while the usage-operator was not running, the deletion time is the last usage capture"
+		case err != nil:
+			errs = errors.Join(errs, fmt.Errorf("error getting mcp of MCPUsage %s: %w", mcpUsage.Name, err))
+			continue
+		case mcpUsage.Status.UsageOperator.MCPUID != "" && mcpUsage.Status.UsageOperator.MCPUID != mcp.UID:
+			// an mcp with the same name exists, but it is a new incarnation
+			deletedAt = lastSeen(mcpUsage)
+			message = "mcp was re-created while the usage-operator was not running, the deletion time is the last usage capture"
+		case mcp.GetDeletionTimestamp() != nil:
+			deletedAt = mcp.GetDeletionTimestamp().Time
+			message = "mcp was deleted while the usage-operator was not running, the deletion time is the deletion timestamp of the mcp"
+		default:
+			continue
+		}
+
+		if deletedAt.IsZero() {
+			log.Info("can't determine deletion time of orphaned MCPUsage", "name", mcpUsage.Name)
+			continue
+		}
+
+		log.Info("marking orphaned MCPUsage as deleted", "name", mcpUsage.Name, "deletedAt", deletedAt)
+		err = u.usageTracker.OrphanEvent(ctx, mcpUsage.Spec.Project, mcpUsage.Spec.Workspace, mcpUsage.Spec.MCP, mcpUsage.Status.UsageOperator.MCPUID, deletedAt, message)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("error marking MCPUsage %s as deleted: %w", mcpUsage.Name, err))
+			continue
+		}
+		u.recorder.Eventf(mcpUsage, nil, corev1.EventTypeWarning, "OrphanDeleted", "MarkDeleted",
+			"%s: %s", message, deletedAt.UTC().Format(time.RFC3339))
+	}
+
+	return
+}
+
+// publishClosedDays publishes a CloudEvent for every day, which was closed since the last run. Like the export, it
+// moves the cursor only after the events were stored in the outbox, so every day is published at least once.
+func (u *UsageRunnable) publishClosedDays(ctx context.Context) error {
+	cursor, err := export.LoadCursor(u.closedDaysCursor)
+	if err != nil {
+		return err
+	}
+
+	var mcpUsages v2.MCPUsageList
+	if err := u.client.List(ctx, &mcpUsages); err != nil {
+		return fmt.Errorf("error listing MCPUsages: %w", err)
+	}
+	byName := make(map[string]*v2.MCPUsage, len(mcpUsages.Items))
+	for i := range mcpUsages.Items {
+		byName[mcpUsages.Items[i].Name] = &mcpUsages.Items[i]
+	}
+
+	records, next := export.Records(mcpUsages.Items, cursor, u.billingLocation, time.Now())
+	// the records are sorted by date and mcp usage, so the charging targets of a day are next to each other
+	for start := 0; start < len(records); {
+		end := start + 1
+		for end < len(records) && records[end].Date == records[start].Date && records[end].MCPUsage == records[start].MCPUsage {
+			end++
+		}
+		if err := u.publishDay(byName[records[start].MCPUsage], records[start:end]); err != nil {
+			return err
+		}
+		start = end
+	}
+
+	return next.Save(u.closedDaysCursor)
+}
+
+// publishDay publishes the records of a day of an MCPUsage as one event. The time of the event is the end of the day.
+func (u *UsageRunnable) publishDay(mcpUsage *v2.MCPUsage, records []export.Record) error {
+	first := records[0]
+	data := cloudevents.DayData{
+		Date:      first.Date,
+		Project:   first.Project,
+		Workspace: first.Workspace,
+		MCP:       first.MCP,
+		MCPUsage:  first.MCPUsage,
+	}
+	for _, record := range records {
+		data.UsageHours += record.Hours
+		data.NonBillableUsageHours += record.NonBillableHours
+		data.ChargingTargets = append(data.ChargingTargets, cloudevents.ChargingTargetHours{
+			ChargingTarget:        record.ChargingTarget,
+			ChargingTargetType:    record.ChargingTargetType,
+			UsageHours:            record.Hours,
+			NonBillableUsageHours: record.NonBillableHours,
+		})
+	}
+
+	day, err := time.ParseInLocation(time.DateOnly, first.Date, usage.BillingLocation(mcpUsage, u.billingLocation))
+	if err != nil {
+		return fmt.Errorf("invalid date %q of MCPUsage %s: %w", first.Date, first.MCPUsage, err)
+	}
+	return u.publisher.Publish(cloudevents.TypeDayClosed, first.MCPUsage+"/"+first.Date, day.AddDate(0, 0, 1), data)
+}
+
+// lastSeen returns the last time the usage-operator knew the mcp of the MCPUsage to exist.
+func lastSeen(mcpUsage *v2.MCPUsage) time.Time {
+	if !mcpUsage.Status.UsageOperator.LastUsageCaptured.IsZero() {
+		return mcpUsage.Status.UsageOperator.LastUsageCaptured.Time
+	}
+	return mcpUsage.Status.UsageOperator.MCPCreatedAt.Time
+}
@@ -0,0 +1,102 @@
+package runnable
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1alpha1 "github.com/openmcp-project/mcp-operator/api/core/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+	"github.com/openmcp-project/usage-operator/internal/usage"
+)
+
+var _ = Describe("Orphan reconciliation", func() {
+	lastCaptured := metav1.NewTime(time.Date(2025, 7, 22, 10, 0, 0, 0, time.UTC))
+	deletionTimestamp := metav1.NewTime(time.Date(2025, 7, 22, 10, 30, 0, 0, time.UTC))
+
+	mcpUsage := func(mcp string, uid string) *v2.MCPUsage {
+		key, err := usage.GetObjectKey("project", "workspace", mcp)
+		Expect(err).ShouldNot(HaveOccurred())
+		return &v2.MCPUsage{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name},
+			Spec:       v2.MCPUsageSpec{Project: "project", Workspace: "workspace", MCP: mcp},
+			Status: v2.MCPUsageStatus{UsageOperator: v2.UsageOperatorStatus{
+				MCPUID:            types.UID(uid),
+				MCPCreatedAt:      metav1.NewTime(lastCaptured.Add(-time.Hour)),
+				LastUsageCaptured: lastCaptured,
+				Lifecycle:         []v2.LifecycleInterval{{CreatedAt: metav1.NewTime(lastCaptured.Add(-time.Hour))}},
+			}},
+		}
+	}
+	mcp := func(name, uid string) *corev1alpha1.ManagedControlPlane {
+		return &corev1alpha1.ManagedControlPlane{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: usage.GetNamespacedName("project", "workspace"),
+			UID:       types.UID(uid),
+		}}
+	}
+
+	var (
+		k8sClient client.Client
+		runnable  UsageRunnable
+		recorder  *events.FakeRecorder
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(v2.AddToScheme(scheme)).To(Succeed())
+		Expect(corev1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		deleting := mcp("mcp-deleting", "uid-deleting")
+		deleting.Finalizers = []string{"usage.openmcp.cloud/finalizer"}
+		deleting.DeletionTimestamp = &deletionTimestamp
+
+		k8sClient = fake.NewClientBuilder().WithScheme(scheme).
+			WithStatusSubresource(&v2.MCPUsage{}).
+			WithObjects(
+				mcpUsage("mcp-existing", "uid-existing"), mcp("mcp-existing", "uid-existing"),
+				mcpUsage("mcp-gone", "uid-gone"),
+				mcpUsage("mcp-recreated", "uid-old"), mcp("mcp-recreated", "uid-new"),
+				mcpUsage("mcp-deleting", "uid-deleting"), deleting,
+			).Build()
+
+		tracker, err := usage.NewUsageTracker(k8sClient)
+		Expect(err).ShouldNot(HaveOccurred())
+		recorder = events.NewFakeRecorder(10)
+		runnable = NewUsageRunnable(k8sClient, tracker, recorder)
+	})
+
+	deletedAt := func(mcp string) time.Time {
+		var result v2.MCPUsage
+		Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(mcpUsage(mcp, "")), &result)).To(Succeed())
+		return result.Status.UsageOperator.MCPDeletedAt.Time
+	}
+
+	It("should mark the MCPUsages of missing, re-created and deleting mcps as deleted", func() {
+		Expect(runnable.reconcileOrphans(context.Background())).To(Succeed())
+
+		Expect(deletedAt("mcp-existing")).Should(BeZero())
+		// the deletion of a missing mcp, or of the previous incarnation of a re-created one, wasn't seen, so the
+		// usage ends with the last capture
+		Expect(deletedAt("mcp-gone")).Should(BeTemporally("==", lastCaptured.Time))
+		Expect(deletedAt("mcp-recreated")).Should(BeTemporally("==", lastCaptured.Time))
+		Expect(deletedAt("mcp-deleting")).Should(BeTemporally("==", deletionTimestamp.Time))
+		Expect(recorder.Events).Should(HaveLen(3))
+	})
+
+	It("should skip MCPUsages, which were already marked as deleted", func() {
+		Expect(runnable.reconcileOrphans(context.Background())).To(Succeed())
+		Expect(recorder.Events).Should(HaveLen(3))
+
+		Expect(runnable.reconcileOrphans(context.Background())).To(Succeed())
+		Expect(recorder.Events).Should(HaveLen(3))
+	})
+})
@@ -0,0 +1,14 @@
+package runnable
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRunnable(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Runnable Suite")
+}
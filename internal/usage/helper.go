@@ -1,16 +1,25 @@
 package usage
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"maps"
+	"slices"
 	"sort"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/google/uuid"
 
-	v1 "github.com/openmcp-project/usage-operator/api/usage/v1"
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+	"github.com/openmcp-project/usage-operator/internal/cloudevents"
+	"github.com/openmcp-project/usage-operator/internal/helper"
 )
 
 const DAY = 24 * time.Hour
@@ -23,40 +32,141 @@ func limitUsage(val time.Duration, max time.Duration) time.Duration {
 	return val
 }
 
-func calculateUsage(start time.Time, end time.Time) (result []v1.DailyUsage) {
-	start = start.UTC()
-	end = end.UTC()
-	duration := start.Sub(end).Abs()
-	return _calculateUsage(start, end, duration)
+// startOfDay returns the midnight of the day of t in the given location.
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// nextDay returns the midnight of the day after the given day. Days are not always 24 hours long, as daylight
+// saving time changes lead to days with 23 or 25 hours.
+func nextDay(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, day.Location())
+}
+
+// calculateUsage splits the time between start and end into the usage per day of the given location. The order of
+// start and end does not matter. The usage is exact, so the sum of all returned entries always equals the time
+// between start and end. The date of every entry is the start of its day.
+func calculateUsage(start time.Time, end time.Time, loc *time.Location) (result []v2.DailyUsage) {
+	if end.Before(start) { // if end is smaller then start, we reverse it
+		start, end = end, start
+	}
+	return _calculateUsage(start.In(loc), end.In(loc), loc)
 }
 
 // recursive function which calculates the usage per day in the time between current and end. Should not be used
 // directly, only through the calculateUsage method.
-func _calculateUsage(current time.Time, end time.Time, duration time.Duration) []v1.DailyUsage {
-	currentDate := current.Truncate(DAY)
-	endDate := end.Truncate(DAY)
-	if currentDate.Equal(endDate) {
+func _calculateUsage(current time.Time, end time.Time, loc *time.Location) []v2.DailyUsage {
+	day := startOfDay(current, loc)
+	next := nextDay(day)
+	if !next.Before(end) {
 		// its the same day, so we need to put the remaining duration onto the current day
-		return []v1.DailyUsage{{
-			Date:  metav1.NewTime(current),
-			Usage: metav1.Duration{Duration: limitUsage(duration, DAY)},
+		return []v2.DailyUsage{{
+			Date:  metav1.NewTime(day),
+			Usage: metav1.Duration{Duration: end.Sub(current)},
 		}}
+	}
+
+	// the rest of the current day, including minutes and seconds
+	usageForTheDay := next.Sub(current)
+
+	return append(_calculateUsage(next, end, loc),
+		v2.DailyUsage{
+			Date:  metav1.NewTime(day),
+			Usage: metav1.Duration{Duration: usageForTheDay},
+		},
+	)
+}
 
+// getRetention returns the retention of the given MCPUsage. The retention annotation takes precedence over the
+// given default. If the annotation can't be parsed, the default is returned together with the error.
+func getRetention(mcpUsage *v2.MCPUsage, defaultRetention time.Duration) (time.Duration, error) {
+	value, ok := mcpUsage.GetAnnotations()[v2.RetentionAnnotation]
+	if !ok {
+		return defaultRetention, nil
 	}
 
-	if end.Before(current) { // if end is smaller then start, we reverse it
-		return _calculateUsage(end, current, duration)
+	retention, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultRetention, fmt.Errorf("can't parse %s annotation %q: %w", v2.RetentionAnnotation, value, err)
+	}
+	if retention <= 0 {
+		return defaultRetention, fmt.Errorf("%s annotation must be a positive duration, got %q", v2.RetentionAnnotation, value)
 	}
 
-	usageForTheDay := DAY - (time.Duration(current.Hour()) * time.Hour)
-	nextDay := currentDate.Add(DAY)
+	return retention, nil
+}
 
-	return append(_calculateUsage(nextDay, end, duration-usageForTheDay),
-		v1.DailyUsage{
-			Date:  metav1.NewTime(current),
-			Usage: metav1.Duration{Duration: limitUsage(usageForTheDay, DAY)},
-		},
-	)
+// normalizePhase returns the phase which is recorded for the given status of an MCP.
+func normalizePhase(status string) string {
+	if status == "" {
+		return v2.MCPPhasePending
+	}
+	return status
+}
+
+// getBillingLocation returns the location which determines the day boundaries of the given MCPUsage. If the MCPUsage
+// has no valid billing timezone, the given default is returned.
+func getBillingLocation(mcpUsage *v2.MCPUsage, defaultLocation *time.Location) (*time.Location, error) {
+	if mcpUsage.Status.UsageOperator.BillingTimezone == "" {
+		return defaultLocation, nil
+	}
+
+	loc, err := time.LoadLocation(mcpUsage.Status.UsageOperator.BillingTimezone)
+	if err != nil {
+		return defaultLocation, fmt.Errorf("can't load billing timezone %q: %w", mcpUsage.Status.UsageOperator.BillingTimezone, err)
+	}
+
+	return loc, nil
+}
+
+// setMCPUID binds the MCPUsage to the MCP with the given uid. It returns whether the labels changed.
+func setMCPUID(mcpUsage *v2.MCPUsage, uid types.UID) bool {
+	if uid == "" {
+		return false
+	}
+	mcpUsage.Status.UsageOperator.MCPUID = uid
+	if mcpUsage.Labels[v2.MCPUIDLabel] == string(uid) {
+		return false
+	}
+	if mcpUsage.Labels == nil {
+		mcpUsage.Labels = map[string]string{}
+	}
+	mcpUsage.Labels[v2.MCPUIDLabel] = string(uid)
+	return true
+}
+
+// ensureLifecycle adds the interval of MCPUsages, which were created before the lifecycle was tracked.
+func ensureLifecycle(mcpUsage *v2.MCPUsage) {
+	if len(mcpUsage.Status.UsageOperator.Lifecycle) > 0 || mcpUsage.Status.UsageOperator.MCPCreatedAt.IsZero() {
+		return
+	}
+	mcpUsage.Status.UsageOperator.Lifecycle = []v2.LifecycleInterval{{
+		CreatedAt: mcpUsage.Status.UsageOperator.MCPCreatedAt,
+		DeletedAt: mcpUsage.Status.UsageOperator.MCPDeletedAt,
+	}}
+}
+
+// startInterval starts a new incarnation of the MCP at the given time. The usage between the previous deletion and
+// the new creation is not captured.
+func startInterval(mcpUsage *v2.MCPUsage, createdAt metav1.Time, uid types.UID) {
+	ensureLifecycle(mcpUsage)
+	mcpUsage.Status.UsageOperator.Lifecycle = append(mcpUsage.Status.UsageOperator.Lifecycle, v2.LifecycleInterval{
+		CreatedAt: createdAt,
+		UID:       uid,
+	})
+	mcpUsage.Status.UsageOperator.MCPCreatedAt = createdAt
+	mcpUsage.Status.UsageOperator.MCPDeletedAt = metav1.Time{}
+	mcpUsage.Status.UsageOperator.LastUsageCaptured = createdAt
+}
+
+// endInterval ends the current incarnation of the MCP at the given time.
+func endInterval(mcpUsage *v2.MCPUsage, deletedAt metav1.Time) {
+	ensureLifecycle(mcpUsage)
+	if last := len(mcpUsage.Status.UsageOperator.Lifecycle) - 1; last >= 0 && mcpUsage.Status.UsageOperator.Lifecycle[last].DeletedAt.IsZero() {
+		mcpUsage.Status.UsageOperator.Lifecycle[last].DeletedAt = deletedAt
+	}
+	mcpUsage.Status.UsageOperator.MCPDeletedAt = deletedAt
 }
 
 func GetNamespacedName(project, workspace string) string {
@@ -76,15 +186,53 @@ func GetObjectKey(project, workspace, mcp string) (client.ObjectKey, error) {
 	}, nil
 }
 
-// merges two DailyUsages where no Date is double
-func MergeDailyUsages(a []v1.DailyUsage, b []v1.DailyUsage) []v1.DailyUsage {
-	aggregatedUsage := make(map[string]metav1.Duration)
+// dateKey returns the calendar date of the given day in the given location. The dates of DailyUsage entries are the
+// start of a day in the billing timezone they were captured in, and they are moved when the billing timezone changes.
+// Entries, which were captured before billing timezones existed, are the start of a UTC day. Only entries, which are
+// neither, are rounded to the nearest midnight.
+func dateKey(date time.Time, loc *time.Location) string {
+	if local := date.In(loc); isMidnight(local) {
+		return local.Format(time.DateOnly)
+	}
+	if utc := date.UTC(); isMidnight(utc) {
+		return utc.Format(time.DateOnly)
+	}
+	return date.In(loc).Add(12 * time.Hour).Format(time.DateOnly)
+}
+
+func isMidnight(t time.Time) bool {
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0
+}
+
+// moveBillingTimezone moves the dates of the daily usage and of the reports from the start of their day in the previous
+// location to the start of the same calendar day in the new location, so they keep their calendar date.
+func moveBillingTimezone(mcpUsage *v2.MCPUsage, from, to *time.Location) {
+	move := func(date metav1.Time) metav1.Time {
+		day, err := time.ParseInLocation(time.DateOnly, dateKey(date.Time, from), to)
+		if err != nil {
+			return date
+		}
+		return metav1.NewTime(day)
+	}
+	for i := range mcpUsage.Status.UsageOperator.Usage {
+		mcpUsage.Status.UsageOperator.Usage[i].Date = move(mcpUsage.Status.UsageOperator.Usage[i].Date)
+	}
+	for i := range mcpUsage.Status.DailyUsageReport {
+		mcpUsage.Status.DailyUsageReport[i].Date = move(mcpUsage.Status.DailyUsageReport[i].Date)
+	}
+}
+
+// merges two DailyUsages where no Date is double. The days are determined in the given location.
+func MergeDailyUsages(a []v2.DailyUsage, b []v2.DailyUsage, loc *time.Location) []v2.DailyUsage {
+	aggregatedUsage := make(map[string]v2.DailyUsage)
 
 	// Helper function to add daily usage to the map
-	addUsageToMap := func(du v1.DailyUsage) {
-		dateKey := du.Date.Format("2006-01-02") // Format to YYYY-MM-DD string
+	addUsageToMap := func(du v2.DailyUsage) {
+		dateKey := dateKey(du.Date.Time, loc) // Format to YYYY-MM-DD string
 		usage := aggregatedUsage[dateKey]
-		usage.Duration += du.Usage.Duration
+		usage.Usage.Duration += du.Usage.Duration
+		usage.NonBillableUsage.Duration += du.NonBillableUsage.Duration
+		usage.ChargingTargets = mergeChargingTargetUsages(usage.ChargingTargets, du.ChargingTargets)
 		aggregatedUsage[dateKey] = usage
 	}
 
@@ -98,15 +246,22 @@ func MergeDailyUsages(a []v1.DailyUsage, b []v1.DailyUsage) []v1.DailyUsage {
 		addUsageToMap(daily)
 	}
 
-	mergedList := make([]v1.DailyUsage, 0, len(aggregatedUsage))
+	mergedList := make([]v2.DailyUsage, 0, len(aggregatedUsage))
 	for dateStr, totalUsage := range aggregatedUsage {
-		t, err := time.Parse("2006-01-02", dateStr)
+		t, err := time.ParseInLocation("2006-01-02", dateStr, loc)
 		if err != nil {
 			continue
 		}
-		mergedList = append(mergedList, v1.DailyUsage{
-			Date:  metav1.Time{Time: t.UTC()}, // Store as UTC for consistency
-			Usage: metav1.Duration{Duration: limitUsage(totalUsage.Duration, DAY)},
+		dayLength := nextDay(t).Sub(t)
+		for i := range totalUsage.ChargingTargets {
+			totalUsage.ChargingTargets[i].Usage.Duration = limitUsage(totalUsage.ChargingTargets[i].Usage.Duration, dayLength)
+			totalUsage.ChargingTargets[i].NonBillableUsage.Duration = limitUsage(totalUsage.ChargingTargets[i].NonBillableUsage.Duration, dayLength)
+		}
+		mergedList = append(mergedList, v2.DailyUsage{
+			Date:             metav1.Time{Time: t},
+			Usage:            metav1.Duration{Duration: limitUsage(totalUsage.Usage.Duration, dayLength)},
+			NonBillableUsage: metav1.Duration{Duration: limitUsage(totalUsage.NonBillableUsage.Duration, dayLength)},
+			ChargingTargets:  totalUsage.ChargingTargets,
 		})
 	}
 
@@ -117,3 +272,375 @@ func MergeDailyUsages(a []v1.DailyUsage, b []v1.DailyUsage) []v1.DailyUsage {
 
 	return mergedList
 }
+
+// asNonBillable moves the usage of the given entries to their non-billable usage.
+func asNonBillable(usages []v2.DailyUsage) []v2.DailyUsage {
+	for i := range usages {
+		usages[i].NonBillableUsage.Duration += usages[i].Usage.Duration
+		usages[i].Usage.Duration = 0
+	}
+	return usages
+}
+
+// mergeChargingTargetUsages sums up the usage of the same charging target. The result is sorted by charging target.
+func mergeChargingTargetUsages(a []v2.ChargingTargetUsage, b []v2.ChargingTargetUsage) []v2.ChargingTargetUsage {
+	if len(b) == 0 {
+		return a
+	}
+
+	merged := slices.Clone(a)
+	for _, usage := range b {
+		i := slices.IndexFunc(merged, func(m v2.ChargingTargetUsage) bool {
+			return m.ChargingTarget == usage.ChargingTarget && m.ChargingTargetType == usage.ChargingTargetType
+		})
+		if i < 0 {
+			merged = append(merged, usage)
+			continue
+		}
+		merged[i].Usage.Duration += usage.Usage.Duration
+		merged[i].NonBillableUsage.Duration += usage.NonBillableUsage.Duration
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].ChargingTarget != merged[j].ChargingTarget {
+			return merged[i].ChargingTarget < merged[j].ChargingTarget
+		}
+		return merged[i].ChargingTargetType < merged[j].ChargingTargetType
+	})
+	return merged
+}
+
+// attributeUsage attributes the usage of the given entries to the given charging target. The usage of a weighted
+// charging target is apportioned between its shares.
+func attributeUsage(usages []v2.DailyUsage, chargingTarget, chargingTargetType string) []v2.DailyUsage {
+	shares := helper.ChargingTargetShares(chargingTarget)
+	for i := range usages {
+		billable := apportion(usages[i].Usage.Duration, shares)
+		nonBillable := apportion(usages[i].NonBillableUsage.Duration, shares)
+		usages[i].ChargingTargets = make([]v2.ChargingTargetUsage, 0, len(shares))
+		for j, share := range shares {
+			usages[i].ChargingTargets = append(usages[i].ChargingTargets, v2.ChargingTargetUsage{
+				ChargingTarget:     share.ChargingTarget,
+				ChargingTargetType: chargingTargetType,
+				Usage:              metav1.Duration{Duration: billable[j]},
+				NonBillableUsage:   metav1.Duration{Duration: nonBillable[j]},
+			})
+		}
+	}
+	return usages
+}
+
+// apportion splits the duration by the weights of the shares. The remainder of the division is added to the last
+// share, so the parts always add up to the duration.
+func apportion(duration time.Duration, shares []v2.ChargingTargetShare) []time.Duration {
+	parts := make([]time.Duration, len(shares))
+	remaining := duration
+	for i, share := range shares {
+		parts[i] = duration * time.Duration(share.Weight) / 100
+		remaining -= parts[i]
+	}
+	parts[len(parts)-1] += remaining
+	return parts
+}
+
+// chargingTargetAt returns the charging target assignment, which was active at the given time. Times before the
+// first assignment belong to the first assignment.
+func chargingTargetAt(history []v2.ChargingTargetAssignment, at time.Time) v2.ChargingTargetAssignment {
+	active := history[0]
+	for _, assignment := range history[1:] {
+		if assignment.EffectiveFrom.After(at) {
+			break
+		}
+		active = assignment
+	}
+	return active
+}
+
+// setChargingTarget assigns the MCPUsage to the given charging target from the given time on. It returns whether the
+// charging target changed. The first assignment is effective from the creation of the MCP, so no usage is left
+// without a charging target. Usage which was captured before the charging target history was recorded is attributed
+// to the charging target which was active at its date.
+func setChargingTarget(mcpUsage *v2.MCPUsage, chargingTarget, chargingTargetType string, at time.Time) bool {
+	status := &mcpUsage.Status.UsageOperator
+	if len(status.ChargingTargetHistory) == 0 && status.ChargingTarget != "" {
+		// MCPUsages which were created before the history was recorded keep their charging target until now
+		status.ChargingTargetHistory = []v2.ChargingTargetAssignment{{
+			ChargingTarget:     status.ChargingTarget,
+			ChargingTargetType: status.ChargingTargetType,
+			EffectiveFrom:      firstCreatedAt(mcpUsage, at),
+		}}
+	}
+
+	split, _ := helper.ParseChargingTargetSplit(chargingTarget)
+	changed := true
+	if last := len(status.ChargingTargetHistory) - 1; last >= 0 {
+		current := status.ChargingTargetHistory[last]
+		changed = current.ChargingTarget != chargingTarget || current.ChargingTargetType != chargingTargetType
+	}
+	if changed {
+		effectiveFrom := metav1.NewTime(at)
+		if len(status.ChargingTargetHistory) == 0 {
+			effectiveFrom = firstCreatedAt(mcpUsage, at)
+		}
+		status.ChargingTargetHistory = append(status.ChargingTargetHistory, v2.ChargingTargetAssignment{
+			ChargingTarget:     chargingTarget,
+			ChargingTargetType: chargingTargetType,
+			Split:              split,
+			EffectiveFrom:      effectiveFrom,
+		})
+	}
+	status.ChargingTarget = chargingTarget
+	status.ChargingTargetType = chargingTargetType
+	status.ChargingTargetSplit = split
+
+	for i := range status.Usage {
+		status.Usage[i] = attributeRemainder(mcpUsage, status.Usage[i])
+	}
+
+	return changed
+}
+
+// firstCreatedAt returns the creation time of the first incarnation of the MCP, or the given time if it is unknown.
+func firstCreatedAt(mcpUsage *v2.MCPUsage, fallback time.Time) metav1.Time {
+	status := mcpUsage.Status.UsageOperator
+	if len(status.Lifecycle) > 0 {
+		return status.Lifecycle[0].CreatedAt
+	}
+	if !status.MCPCreatedAt.IsZero() {
+		return status.MCPCreatedAt
+	}
+	return metav1.NewTime(fallback)
+}
+
+// missingChargingTarget is the charging target of MCPUsages, whose charging target can't be determined.
+const missingChargingTarget = "missing"
+
+// isChargingTargetMissing returns whether the charging target of the MCPUsage couldn't be determined.
+func isChargingTargetMissing(mcpUsage *v2.MCPUsage) bool {
+	chargingTarget := mcpUsage.Status.UsageOperator.ChargingTarget
+	return chargingTarget == "" || chargingTarget == missingChargingTarget
+}
+
+// BillingLocation returns the location, which determines the day boundaries of the MCPUsage. If the MCPUsage has no
+// valid billing timezone, the given default is returned.
+func BillingLocation(mcpUsage *v2.MCPUsage, defaultLocation *time.Location) *time.Location {
+	loc, _ := getBillingLocation(mcpUsage, defaultLocation)
+	return loc
+}
+
+// BillingDate returns the calendar date of the DailyUsage entry in the billing timezone of the MCPUsage. If the
+// MCPUsage has no valid billing timezone, the given default is used.
+func BillingDate(mcpUsage *v2.MCPUsage, usage v2.DailyUsage, defaultLocation *time.Location) string {
+	loc, _ := getBillingLocation(mcpUsage, defaultLocation)
+	return dateKey(usage.Date.Time, loc)
+}
+
+// ChargingTargetUsages returns the usage of the DailyUsage entry split by charging target. Entries which were captured
+// before the split was recorded are attributed to the charging target, which was active at their date.
+func ChargingTargetUsages(mcpUsage *v2.MCPUsage, usage v2.DailyUsage) []v2.ChargingTargetUsage {
+	return attributeRemainder(mcpUsage, usage).ChargingTargets
+}
+
+// attributeRemainder attributes the part of the usage of the DailyUsage entry, which isn't attributed to a charging
+// target yet, to the charging target, which was active at its date. This is the usage, which was captured without a
+// charging target or before the split was recorded. Without any charging target, the entry is returned unchanged.
+func attributeRemainder(mcpUsage *v2.MCPUsage, usage v2.DailyUsage) v2.DailyUsage {
+	remainder := v2.DailyUsage{Date: usage.Date, Usage: usage.Usage, NonBillableUsage: usage.NonBillableUsage}
+	for _, chargingTargetUsage := range usage.ChargingTargets {
+		remainder.Usage.Duration -= chargingTargetUsage.Usage.Duration
+		remainder.NonBillableUsage.Duration -= chargingTargetUsage.NonBillableUsage.Duration
+	}
+	if remainder.Usage.Duration <= 0 && remainder.NonBillableUsage.Duration <= 0 {
+		return usage
+	}
+	remainder.Usage.Duration = max(remainder.Usage.Duration, 0)
+	remainder.NonBillableUsage.Duration = max(remainder.NonBillableUsage.Duration, 0)
+
+	status := mcpUsage.Status.UsageOperator
+	chargingTarget, chargingTargetType := status.ChargingTarget, status.ChargingTargetType
+	if len(status.ChargingTargetHistory) > 0 {
+		active := chargingTargetAt(status.ChargingTargetHistory, usage.Date.Time)
+		chargingTarget, chargingTargetType = active.ChargingTarget, active.ChargingTargetType
+	}
+	if chargingTarget == "" {
+		return usage
+	}
+
+	remainder = attributeUsage([]v2.DailyUsage{remainder}, chargingTarget, chargingTargetType)[0]
+	usage.ChargingTargets = mergeChargingTargetUsages(usage.ChargingTargets, remainder.ChargingTargets)
+	return usage
+}
+
+// IsDayClosed returns whether the usage of the DailyUsage entry is final at the given time. This is the case, once the
+// usage was captured until the end of the day, or the day has ended after the MCP was deleted.
+func IsDayClosed(mcpUsage *v2.MCPUsage, usage v2.DailyUsage, defaultLocation *time.Location, now time.Time) bool {
+	loc, _ := getBillingLocation(mcpUsage, defaultLocation)
+	day, err := time.ParseInLocation(time.DateOnly, dateKey(usage.Date.Time, loc), loc)
+	if err != nil {
+		return false
+	}
+	end := nextDay(day)
+
+	status := mcpUsage.Status.UsageOperator
+	if !status.LastUsageCaptured.Time.Before(end) {
+		return true
+	}
+	return !status.MCPDeletedAt.IsZero() && !now.Before(end)
+}
+
+// monthFormat is the format of the months of MCPUsageMonthly resources.
+const monthFormat = "2006-01"
+
+// MonthlyName returns the name of the MCPUsageMonthly, which contains the usage of the MCPUsage in the given month.
+func MonthlyName(mcpUsage, month string) string {
+	return mcpUsage + "-" + month
+}
+
+// usageMonths returns the calendar months of the DailyUsage entries of the MCPUsage in its billing timezone, oldest
+// first.
+func usageMonths(mcpUsage *v2.MCPUsage, defaultLocation *time.Location) []string {
+	var months []string
+	for _, usage := range mcpUsage.Status.UsageOperator.Usage {
+		month := BillingDate(mcpUsage, usage, defaultLocation)[:len(monthFormat)]
+		if !slices.Contains(months, month) {
+			months = append(months, month)
+		}
+	}
+	slices.Sort(months)
+	return months
+}
+
+// isMonthClosed returns whether the usage of the month is final at the given time. Like a day, a month is closed once
+// the usage was captured until its end, or the month has ended after the MCP was deleted.
+func isMonthClosed(mcpUsage *v2.MCPUsage, month string, defaultLocation *time.Location, now time.Time) bool {
+	loc, _ := getBillingLocation(mcpUsage, defaultLocation)
+	start, err := time.ParseInLocation(monthFormat, month, loc)
+	if err != nil {
+		return false
+	}
+	end := start.AddDate(0, 1, 0)
+
+	status := mcpUsage.Status.UsageOperator
+	if !status.LastUsageCaptured.Time.Before(end) {
+		return true
+	}
+	return !status.MCPDeletedAt.IsZero() && !now.Before(end)
+}
+
+// rollupMonth returns the usage of the MCPUsage in the given month, based on the previous rollup of the month. Days of
+// the previous rollup are kept, even if they were pruned from the MCPUsage since, and days which are still part of the
+// MCPUsage replace them. Every day is split by charging target, so the rollup doesn't depend on the MCPUsage anymore.
+func rollupMonth(previous v2.MCPUsageMonthlyStatus, mcpUsage *v2.MCPUsage, month string, defaultLocation *time.Location) v2.MCPUsageMonthlyStatus {
+	loc, _ := getBillingLocation(mcpUsage, defaultLocation)
+
+	days := map[string]v2.DailyUsage{}
+	for _, usage := range previous.DailyUsage {
+		days[dateKey(usage.Date.Time, loc)] = usage
+	}
+	for _, usage := range mcpUsage.Status.UsageOperator.Usage {
+		date := dateKey(usage.Date.Time, loc)
+		if date[:len(monthFormat)] != month {
+			continue
+		}
+		usage.ChargingTargets = ChargingTargetUsages(mcpUsage, usage)
+		days[date] = usage
+	}
+
+	rollup := v2.MCPUsageMonthlyStatus{BillingTimezone: loc.String()}
+	for _, date := range slices.Sorted(maps.Keys(days)) {
+		usage := days[date]
+		rollup.DailyUsage = append(rollup.DailyUsage, usage)
+		rollup.Usage.Duration += usage.Usage.Duration
+		rollup.NonBillableUsage.Duration += usage.NonBillableUsage.Duration
+		rollup.ChargingTargets = mergeChargingTargetUsages(rollup.ChargingTargets, usage.ChargingTargets)
+	}
+
+	// the assignments, which were active during the month, are the last one before the month and all within it
+	start, err := time.ParseInLocation(monthFormat, month, loc)
+	if err != nil {
+		return rollup
+	}
+	end := start.AddDate(0, 1, 0)
+	history := mcpUsage.Status.UsageOperator.ChargingTargetHistory
+	for i, assignment := range history {
+		if !assignment.EffectiveFrom.Time.Before(end) {
+			break
+		}
+		if i+1 < len(history) && !history[i+1].EffectiveFrom.Time.After(start) {
+			continue
+		}
+		rollup.ChargingTargetHistory = append(rollup.ChargingTargetHistory, assignment)
+	}
+	return rollup
+}
+
+// mcpData returns the data of the CloudEvents about the creation and deletion of the current incarnation of the MCP.
+func mcpData(mcpUsage *v2.MCPUsage) cloudevents.MCPData {
+	status := mcpUsage.Status.UsageOperator
+	data := cloudevents.MCPData{
+		Project:        mcpUsage.Spec.Project,
+		Workspace:      mcpUsage.Spec.Workspace,
+		MCP:            mcpUsage.Spec.MCP,
+		MCPUsage:       mcpUsage.Name,
+		MCPUID:         string(status.MCPUID),
+		ChargingTarget: status.ChargingTarget,
+		CreatedAt:      status.MCPCreatedAt.Time,
+	}
+	if !status.MCPDeletedAt.IsZero() {
+		data.DeletedAt = &status.MCPDeletedAt.Time
+	}
+	return data
+}
+
+// contentHash returns the hash of the usage of a day. It covers the usage and its split between the charging targets,
+// but neither the stored hash itself nor the date, which moves with the billing timezone.
+func contentHash(usage v2.DailyUsage) string {
+	usage.ContentHash = ""
+	usage.Date = metav1.Time{}
+	data, err := json.Marshal(usage)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// findReport returns the report of the metering operator for the day with the given date, or nil if the day wasn't
+// reported.
+func findReport(mcpUsage *v2.MCPUsage, date metav1.Time) *v2.DailyUsageReport {
+	for i := range mcpUsage.Status.DailyUsageReport {
+		if mcpUsage.Status.DailyUsageReport[i].Date.Equal(&date) {
+			return &mcpUsage.Status.DailyUsageReport[i]
+		}
+	}
+	return nil
+}
+
+// setContentHashes updates the content hash of every day and marks the reports of days, which changed after they were
+// reported, as superseded. The dates of the superseded reports are returned.
+func setContentHashes(mcpUsage *v2.MCPUsage) []string {
+	var superseded []string
+	for i := range mcpUsage.Status.UsageOperator.Usage {
+		usage := &mcpUsage.Status.UsageOperator.Usage[i]
+		usage.ContentHash = contentHash(*usage)
+
+		report := findReport(mcpUsage, usage.Date)
+		if report == nil || report.Status != v2.ReportStatusReported || report.ContentHash == "" || report.ContentHash == usage.ContentHash {
+			continue
+		}
+		report.Status = v2.ReportStatusSuperseded
+		report.Message = "usage changed after it was reported"
+		superseded = append(superseded, usage.Date.UTC().Format(time.RFC3339))
+	}
+	return superseded
+}
+
+// isAcknowledged returns whether the metering operator reported the day with its current content. Reports without a
+// content hash acknowledge any content, as they were written before the hash existed.
+func isAcknowledged(mcpUsage *v2.MCPUsage, usage v2.DailyUsage) bool {
+	report := findReport(mcpUsage, usage.Date)
+	if report == nil || report.Status != v2.ReportStatusReported {
+		return false
+	}
+	return report.ContentHash == "" || report.ContentHash == contentHash(usage)
+}
@@ -0,0 +1,115 @@
+package usage
+
+import (
+	"math/rand"
+	"testing/quick"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+)
+
+// propertyEpoch is the earliest point in time used by the generated inputs.
+var propertyEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// propertySpan limits the generated start times, as zone transitions far in the future are only approximated.
+const propertySpan = 2 * 365 * 24 * 60 * 60
+
+func sumUsage(usages []v2.DailyUsage) time.Duration {
+	var sum time.Duration
+	for _, usage := range usages {
+		sum += usage.Usage.Duration
+	}
+	return sum
+}
+
+// randomTime returns a random point in time within the propertySpan after propertyEpoch.
+func randomTime(r *rand.Rand) time.Time {
+	return propertyEpoch.Add(time.Duration(r.Int63n(propertySpan)) * time.Second)
+}
+
+// propertyLocations are the billing timezones the properties are checked with.
+var propertyLocations = []string{"UTC", "Europe/Berlin", "America/New_York", "Asia/Kolkata"}
+
+func quickConfig() *quick.Config {
+	return &quick.Config{
+		MaxCount: 2000,
+		Rand:     rand.New(rand.NewSource(GinkgoRandomSeed())),
+	}
+}
+
+var _ = Describe("Usage calculation properties", func() {
+	for _, name := range propertyLocations {
+		Context("in "+name, func() {
+			var loc *time.Location
+
+			BeforeEach(func() {
+				var err error
+				loc, err = time.LoadLocation(name)
+				Expect(err).ShouldNot(HaveOccurred())
+			})
+
+			It("should split the elapsed time exactly", func() {
+				property := func(startOffset, length uint32) bool {
+					start := propertyEpoch.Add(time.Duration(startOffset) * time.Millisecond)
+					end := start.Add(time.Duration(length) * time.Millisecond * 2)
+
+					return sumUsage(calculateUsage(start, end, loc)) == end.Sub(start)
+				}
+				Expect(quick.Check(property, quickConfig())).To(Succeed())
+			})
+
+			It("should put every entry onto a different day without exceeding its length", func() {
+				property := func(startOffset, length uint32) bool {
+					start := propertyEpoch.Add(time.Duration(startOffset%propertySpan) * time.Second)
+					end := start.Add(time.Duration(length%(90*24*60)) * time.Minute)
+
+					seen := map[time.Time]bool{}
+					for _, usage := range calculateUsage(start, end, loc) {
+						day := usage.Date.Time
+						if seen[day] || !day.Equal(startOfDay(day, loc)) || usage.Usage.Duration > nextDay(day).Sub(day) || usage.Usage.Duration < 0 {
+							return false
+						}
+						seen[day] = true
+					}
+					return true
+				}
+				Expect(quick.Check(property, quickConfig())).To(Succeed())
+			})
+
+			It("should be independent of the order of start and end", func() {
+				property := func(startOffset, length uint32) bool {
+					start := propertyEpoch.Add(time.Duration(startOffset%propertySpan) * time.Second)
+					end := start.Add(time.Duration(length%(365*24*60*60)) * time.Second)
+
+					return sumUsage(calculateUsage(start, end, loc)) == sumUsage(calculateUsage(end, start, loc))
+				}
+				Expect(quick.Check(property, quickConfig())).To(Succeed())
+			})
+
+			It("should sum up to the elapsed time over many truncated captures", func() {
+				r := rand.New(rand.NewSource(GinkgoRandomSeed()))
+				granularities := []time.Duration{time.Second, time.Minute, 15 * time.Minute, time.Hour}
+
+				for range 200 {
+					granularity := granularities[r.Intn(len(granularities))]
+					createdAt := randomTime(r).Truncate(granularity)
+
+					// simulates the scheduled event, which captures the usage at irregular intervals
+					lastUsageCaptured := createdAt
+					var usages []v2.DailyUsage
+					for range r.Intn(100) + 1 {
+						now := lastUsageCaptured.Add(time.Duration(r.Int63n(int64(3 * DAY)))).Truncate(granularity)
+						usages = MergeDailyUsages(calculateUsage(now, lastUsageCaptured, loc), usages, loc)
+						lastUsageCaptured = now
+					}
+
+					Expect(sumUsage(usages)).Should(Equal(lastUsageCaptured.Sub(createdAt)),
+						"granularity %s, created at %s, last captured at %s", granularity, createdAt, lastUsageCaptured)
+				}
+			})
+		})
+	}
+})
@@ -7,7 +7,7 @@ import (
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	v1 "github.com/openmcp-project/usage-operator/api/usage/v1"
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
 )
 
 var _ = Describe("Helper Module", func() {
@@ -22,7 +22,7 @@ var _ = Describe("Helper Module", func() {
 			start := end.Add(-7 * 24 * time.Hour)
 			start = start.Truncate(24 * time.Hour)
 
-			result := calculateUsage(start, end)
+			result := calculateUsage(start, end, time.UTC)
 
 			Expect(result).Should(HaveLen(8))
 
@@ -32,12 +32,12 @@ var _ = Describe("Helper Module", func() {
 				Expect(usage.Usage.Duration).Should(Equal(24 * time.Hour))
 			}
 
-			reversed := calculateUsage(end, start)
+			reversed := calculateUsage(end, start, time.UTC)
 			Expect(result).Should(Equal(reversed), "the calculation must be reversed the same")
 		})
 
 		It("should merge dailyusage", func() {
-			dailyUsage1 := []v1.DailyUsage{
+			dailyUsage1 := []v2.DailyUsage{
 				{
 					Date: metav1.NewTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
 					Usage: metav1.Duration{
@@ -52,7 +52,7 @@ var _ = Describe("Helper Module", func() {
 				},
 			}
 
-			dailyUsage2 := []v1.DailyUsage{
+			dailyUsage2 := []v2.DailyUsage{
 				{
 					Date: metav1.NewTime(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)),
 					Usage: metav1.Duration{
@@ -67,7 +67,7 @@ var _ = Describe("Helper Module", func() {
 				},
 			}
 
-			mergedUsages := MergeDailyUsages(dailyUsage1, dailyUsage2)
+			mergedUsages := MergeDailyUsages(dailyUsage1, dailyUsage2, time.UTC)
 
 			Expect(mergedUsages).Should(HaveLen(3))
 
@@ -75,6 +75,519 @@ var _ = Describe("Helper Module", func() {
 			Expect(mergedUsages[1].Usage.Hours()).Should(Equal(24.0))
 		})
 	})
+	Context("Non-billable usage", func() {
+		It("should move the usage to the non-billable usage", func() {
+			usages := asNonBillable(calculateUsage(
+				time.Date(2025, 1, 1, 22, 0, 0, 0, time.UTC),
+				time.Date(2025, 1, 2, 2, 0, 0, 0, time.UTC),
+				time.UTC,
+			))
+
+			Expect(usages).Should(HaveLen(2))
+			for _, usage := range usages {
+				Expect(usage.Usage.Duration).Should(BeZero())
+				Expect(usage.NonBillableUsage.Duration).Should(Equal(2 * time.Hour))
+			}
+		})
+
+		It("should merge billable and non-billable usage separately", func() {
+			date := metav1.NewTime(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC))
+			mergedUsages := MergeDailyUsages(
+				[]v2.DailyUsage{{Date: date, Usage: metav1.Duration{Duration: 4 * time.Hour}}},
+				[]v2.DailyUsage{{Date: date, NonBillableUsage: metav1.Duration{Duration: 2 * time.Hour}}},
+				time.UTC,
+			)
+
+			Expect(mergedUsages).Should(HaveLen(1))
+			Expect(mergedUsages[0].Usage.Duration).Should(Equal(4 * time.Hour))
+			Expect(mergedUsages[0].NonBillableUsage.Duration).Should(Equal(2 * time.Hour))
+		})
+	})
+
+	Context("Billing timezone", func() {
+		var berlin, newYork *time.Location
+
+		BeforeEach(func() {
+			var err error
+			berlin, err = time.LoadLocation("Europe/Berlin")
+			Expect(err).ShouldNot(HaveOccurred())
+			newYork, err = time.LoadLocation("America/New_York")
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should split the usage at the local day boundaries", func() {
+			start := time.Date(2025, 7, 21, 21, 30, 0, 0, time.UTC) // 23:30 in Berlin
+			end := time.Date(2025, 7, 21, 23, 0, 0, 0, time.UTC)    // 01:00 in Berlin
+
+			result := calculateUsage(start, end, berlin)
+			Expect(result).Should(HaveLen(2))
+
+			Expect(result[0].Date.Time.Equal(time.Date(2025, 7, 22, 0, 0, 0, 0, berlin))).Should(BeTrue())
+			Expect(result[0].Usage.Duration).Should(Equal(time.Hour))
+			Expect(result[1].Date.Time.Equal(time.Date(2025, 7, 21, 0, 0, 0, 0, berlin))).Should(BeTrue())
+			Expect(result[1].Usage.Duration).Should(Equal(30 * time.Minute))
+		})
+
+		It("should handle days with 23 and 25 hours", func() {
+			for _, day := range []struct {
+				date   time.Time
+				length time.Duration
+			}{
+				{date: time.Date(2025, 3, 30, 0, 0, 0, 0, berlin), length: 23 * time.Hour},
+				{date: time.Date(2025, 10, 26, 0, 0, 0, 0, berlin), length: 25 * time.Hour},
+				{date: time.Date(2025, 3, 9, 0, 0, 0, 0, newYork), length: 23 * time.Hour},
+				{date: time.Date(2025, 11, 2, 0, 0, 0, 0, newYork), length: 25 * time.Hour},
+			} {
+				loc := day.date.Location()
+				start := day.date.Add(-time.Hour)
+				end := nextDay(day.date).Add(time.Hour)
+
+				usages := MergeDailyUsages(calculateUsage(start, end, loc), nil, loc)
+				Expect(usages).Should(HaveLen(3))
+				Expect(usages[1].Date.Time.Equal(day.date)).Should(BeTrue())
+				Expect(usages[1].Usage.Duration).Should(Equal(day.length), "day %s", day.date)
+			}
+		})
+
+		It("should keep the calendar date of entries when the billing timezone changes", func() {
+			usages := []v2.DailyUsage{
+				{
+					Date:  metav1.NewTime(time.Date(2025, 7, 22, 0, 0, 0, 0, berlin)),
+					Usage: metav1.Duration{Duration: 4 * time.Hour},
+				},
+			}
+
+			merged := MergeDailyUsages(nil, usages, newYork)
+			Expect(merged).Should(HaveLen(1))
+			Expect(merged[0].Date.Time.Equal(time.Date(2025, 7, 22, 0, 0, 0, 0, newYork))).Should(BeTrue())
+		})
+
+		It("should keep the calendar date of legacy utc entries in timezones beyond 12 hours", func() {
+			for _, name := range []string{"Pacific/Kiritimati", "Pacific/Tongatapu", "Pacific/Pago_Pago"} {
+				loc, err := time.LoadLocation(name)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(dateKey(time.Date(2025, 7, 22, 0, 0, 0, 0, time.UTC), loc)).Should(Equal("2025-07-22"), name)
+				Expect(dateKey(time.Date(2025, 7, 22, 0, 0, 0, 0, loc), loc)).Should(Equal("2025-07-22"), name)
+			}
+		})
+
+		It("should move the entries and reports when the billing timezone changes", func() {
+			honolulu, err := time.LoadLocation("Pacific/Honolulu")
+			Expect(err).ShouldNot(HaveOccurred())
+			kiritimati, err := time.LoadLocation("Pacific/Kiritimati")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			date := metav1.NewTime(time.Date(2025, 7, 22, 0, 0, 0, 0, honolulu))
+			mcpUsage := &v2.MCPUsage{}
+			mcpUsage.Status.UsageOperator.Usage = []v2.DailyUsage{{Date: date, Usage: metav1.Duration{Duration: 4 * time.Hour}}}
+			mcpUsage.Status.DailyUsageReport = []v2.DailyUsageReport{{Date: date, Status: v2.ReportStatusReported}}
+			hash := contentHash(mcpUsage.Status.UsageOperator.Usage[0])
+
+			// the offsets differ by a whole day, so rounding to the nearest midnight can't tell the days apart
+			moveBillingTimezone(mcpUsage, honolulu, kiritimati)
+			moved := time.Date(2025, 7, 22, 0, 0, 0, 0, kiritimati)
+			Expect(mcpUsage.Status.UsageOperator.Usage[0].Date.Time.Equal(moved)).Should(BeTrue())
+			Expect(mcpUsage.Status.DailyUsageReport[0].Date.Time.Equal(moved)).Should(BeTrue())
+			Expect(dateKey(mcpUsage.Status.UsageOperator.Usage[0].Date.Time, kiritimati)).Should(Equal("2025-07-22"))
+			Expect(contentHash(mcpUsage.Status.UsageOperator.Usage[0])).Should(Equal(hash))
+		})
+
+		It("should use the billing timezone of the mcp usage", func() {
+			mcpUsage := &v2.MCPUsage{
+				Status: v2.MCPUsageStatus{
+					UsageOperator: v2.UsageOperatorStatus{BillingTimezone: "Europe/Berlin"},
+				},
+			}
+			loc, err := getBillingLocation(mcpUsage, time.UTC)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(loc.String()).Should(Equal("Europe/Berlin"))
+
+			mcpUsage.Status.UsageOperator.BillingTimezone = "Mars/Olympus_Mons"
+			loc, err = getBillingLocation(mcpUsage, time.UTC)
+			Expect(err).Should(HaveOccurred())
+			Expect(loc).Should(Equal(time.UTC))
+		})
+	})
+	Context("Retention", func() {
+		It("should use the default retention without annotation", func() {
+			retention, err := getRetention(&v2.MCPUsage{}, time.Hour)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(retention).Should(Equal(time.Hour))
+		})
+
+		It("should use the retention annotation", func() {
+			mcpUsage := &v2.MCPUsage{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{v2.RetentionAnnotation: "2208h"},
+				},
+			}
+			retention, err := getRetention(mcpUsage, time.Hour)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(retention).Should(Equal(92 * 24 * time.Hour))
+		})
+
+		It("should fall back to the default retention for an invalid annotation", func() {
+			mcpUsage := &v2.MCPUsage{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{v2.RetentionAnnotation: "three months"},
+				},
+			}
+			retention, err := getRetention(mcpUsage, time.Hour)
+			Expect(err).Should(HaveOccurred())
+			Expect(retention).Should(Equal(time.Hour))
+		})
+	})
+	Context("Lifecycle", func() {
+		created := metav1.NewTime(time.Date(2025, 7, 1, 8, 0, 0, 0, time.UTC))
+		deleted := metav1.NewTime(time.Date(2025, 7, 3, 8, 0, 0, 0, time.UTC))
+		recreated := metav1.NewTime(time.Date(2025, 7, 5, 8, 0, 0, 0, time.UTC))
+
+		It("should keep the interval of a legacy mcp usage when it is re-created", func() {
+			mcpUsage := &v2.MCPUsage{
+				Status: v2.MCPUsageStatus{
+					UsageOperator: v2.UsageOperatorStatus{
+						MCPCreatedAt:      created,
+						MCPDeletedAt:      deleted,
+						LastUsageCaptured: deleted,
+					},
+				},
+			}
+
+			startInterval(mcpUsage, recreated, "uid-2")
+
+			Expect(mcpUsage.Status.UsageOperator.Lifecycle).Should(Equal([]v2.LifecycleInterval{
+				{CreatedAt: created, DeletedAt: deleted},
+				{CreatedAt: recreated, UID: "uid-2"},
+			}))
+			Expect(mcpUsage.Status.UsageOperator.MCPCreatedAt).Should(Equal(recreated))
+			Expect(mcpUsage.Status.UsageOperator.MCPDeletedAt.IsZero()).Should(BeTrue())
+			Expect(mcpUsage.Status.UsageOperator.LastUsageCaptured).Should(Equal(recreated))
+		})
+
+		It("should only end the current interval", func() {
+			mcpUsage := &v2.MCPUsage{}
+			startInterval(mcpUsage, created, "uid-1")
+			endInterval(mcpUsage, deleted)
+			startInterval(mcpUsage, recreated, "uid-2")
+			endInterval(mcpUsage, metav1.NewTime(recreated.Add(time.Hour)))
+
+			Expect(mcpUsage.Status.UsageOperator.Lifecycle).Should(HaveLen(2))
+			Expect(mcpUsage.Status.UsageOperator.Lifecycle[0].DeletedAt).Should(Equal(deleted))
+			Expect(mcpUsage.Status.UsageOperator.Lifecycle[1].DeletedAt.Time).Should(Equal(recreated.Add(time.Hour)))
+			Expect(mcpUsage.Status.UsageOperator.MCPDeletedAt.Time).Should(Equal(recreated.Add(time.Hour)))
+		})
+	})
+	Context("Charging target history", func() {
+		created := metav1.NewTime(time.Date(2025, 7, 1, 8, 0, 0, 0, time.UTC))
+		changed := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+
+		It("should make the first charging target effective from the creation", func() {
+			mcpUsage := &v2.MCPUsage{}
+			startInterval(mcpUsage, created, "uid-1")
+
+			Expect(setChargingTarget(mcpUsage, "cc-1", "cost-center", changed)).Should(BeTrue())
+			Expect(setChargingTarget(mcpUsage, "cc-1", "cost-center", changed.Add(time.Hour))).Should(BeFalse())
+
+			Expect(mcpUsage.Status.UsageOperator.ChargingTargetHistory).Should(Equal([]v2.ChargingTargetAssignment{
+				{ChargingTarget: "cc-1", ChargingTargetType: "cost-center", EffectiveFrom: created},
+			}))
+		})
+
+		It("should keep the charging target of a legacy mcp usage until the change", func() {
+			mcpUsage := &v2.MCPUsage{
+				Status: v2.MCPUsageStatus{
+					UsageOperator: v2.UsageOperatorStatus{
+						ChargingTarget: "cc-1",
+						MCPCreatedAt:   created,
+						Usage: []v2.DailyUsage{
+							{
+								Date:  metav1.NewTime(time.Date(2025, 7, 14, 0, 0, 0, 0, time.UTC)),
+								Usage: metav1.Duration{Duration: 24 * time.Hour},
+							},
+						},
+					},
+				},
+			}
+
+			Expect(setChargingTarget(mcpUsage, "cc-2", "", changed)).Should(BeTrue())
+
+			Expect(mcpUsage.Status.UsageOperator.ChargingTarget).Should(Equal("cc-2"))
+			Expect(mcpUsage.Status.UsageOperator.ChargingTargetHistory).Should(Equal([]v2.ChargingTargetAssignment{
+				{ChargingTarget: "cc-1", EffectiveFrom: created},
+				{ChargingTarget: "cc-2", EffectiveFrom: metav1.NewTime(changed)},
+			}))
+			Expect(mcpUsage.Status.UsageOperator.Usage[0].ChargingTargets).Should(Equal([]v2.ChargingTargetUsage{
+				{ChargingTarget: "cc-1", Usage: metav1.Duration{Duration: 24 * time.Hour}},
+			}))
+		})
+
+		It("should split a day between the charging targets", func() {
+			before := attributeUsage(calculateUsage(time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC), changed, time.UTC), "cc-1", "")
+			after := attributeUsage(calculateUsage(changed, time.Date(2025, 7, 15, 20, 0, 0, 0, time.UTC), time.UTC), "cc-2", "")
+
+			merged := MergeDailyUsages(after, before, time.UTC)
+			Expect(merged).Should(HaveLen(1))
+			Expect(merged[0].Usage.Duration).Should(Equal(20 * time.Hour))
+			Expect(merged[0].ChargingTargets).Should(Equal([]v2.ChargingTargetUsage{
+				{ChargingTarget: "cc-1", Usage: metav1.Duration{Duration: 12 * time.Hour}},
+				{ChargingTarget: "cc-2", Usage: metav1.Duration{Duration: 8 * time.Hour}},
+			}))
+		})
+
+		It("should apportion the usage of a weighted charging target", func() {
+			usages := attributeUsage([]v2.DailyUsage{{
+				Date:             metav1.NewTime(time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC)),
+				Usage:            metav1.Duration{Duration: 10 * time.Hour},
+				NonBillableUsage: metav1.Duration{Duration: time.Second},
+			}}, "cc-1:70,cc-2:30", "cost-center")
+
+			Expect(usages[0].ChargingTargets).Should(Equal([]v2.ChargingTargetUsage{
+				{
+					ChargingTarget:     "cc-1",
+					ChargingTargetType: "cost-center",
+					Usage:              metav1.Duration{Duration: 7 * time.Hour},
+					NonBillableUsage:   metav1.Duration{Duration: 700 * time.Millisecond},
+				},
+				{
+					ChargingTarget:     "cc-2",
+					ChargingTargetType: "cost-center",
+					Usage:              metav1.Duration{Duration: 3 * time.Hour},
+					NonBillableUsage:   metav1.Duration{Duration: 300 * time.Millisecond},
+				},
+			}))
+		})
+
+		It("should not lose any time when apportioning", func() {
+			parts := apportion(time.Duration(1001), []v2.ChargingTargetShare{
+				{ChargingTarget: "cc-1", Weight: 33},
+				{ChargingTarget: "cc-2", Weight: 33},
+				{ChargingTarget: "cc-3", Weight: 34},
+			})
+			Expect(parts[0] + parts[1] + parts[2]).Should(Equal(time.Duration(1001)))
+		})
+
+		It("should record the split of a weighted charging target", func() {
+			mcpUsage := &v2.MCPUsage{}
+			startInterval(mcpUsage, created, "uid-1")
+
+			Expect(setChargingTarget(mcpUsage, "cc-1:70,cc-2:30", "", changed)).Should(BeTrue())
+			Expect(mcpUsage.Status.UsageOperator.ChargingTargetSplit).Should(Equal([]v2.ChargingTargetShare{
+				{ChargingTarget: "cc-1", Weight: 70},
+				{ChargingTarget: "cc-2", Weight: 30},
+			}))
+			Expect(mcpUsage.Status.UsageOperator.ChargingTargetHistory[0].Split).Should(HaveLen(2))
+		})
+
+		It("should attribute unsplit days to the charging target active at their date", func() {
+			mcpUsage := &v2.MCPUsage{
+				Status: v2.MCPUsageStatus{
+					UsageOperator: v2.UsageOperatorStatus{
+						ChargingTarget: "cc-2",
+						ChargingTargetHistory: []v2.ChargingTargetAssignment{
+							{ChargingTarget: "cc-1", EffectiveFrom: created},
+							{ChargingTarget: "cc-2", EffectiveFrom: metav1.NewTime(changed)},
+						},
+					},
+				},
+			}
+			day := v2.DailyUsage{
+				Date:  metav1.NewTime(time.Date(2025, 7, 14, 0, 0, 0, 0, time.UTC)),
+				Usage: metav1.Duration{Duration: 24 * time.Hour},
+			}
+
+			Expect(ChargingTargetUsages(mcpUsage, day)).Should(Equal([]v2.ChargingTargetUsage{
+				{ChargingTarget: "cc-1", Usage: metav1.Duration{Duration: 24 * time.Hour}},
+			}))
+			Expect(BillingDate(mcpUsage, day, time.UTC)).Should(Equal("2025-07-14"))
+		})
+
+		It("should attribute the unattributed usage of a day before merging an attributed delta", func() {
+			mcpUsage := &v2.MCPUsage{
+				Status: v2.MCPUsageStatus{
+					UsageOperator: v2.UsageOperatorStatus{
+						ChargingTarget: "cc-2",
+						ChargingTargetHistory: []v2.ChargingTargetAssignment{
+							{ChargingTarget: "cc-1", EffectiveFrom: created},
+							{ChargingTarget: "cc-2", EffectiveFrom: metav1.NewTime(changed)},
+						},
+					},
+				},
+			}
+			day := v2.DailyUsage{
+				Date:  metav1.NewTime(time.Date(2025, 7, 14, 0, 0, 0, 0, time.UTC)),
+				Usage: metav1.Duration{Duration: 10 * time.Hour},
+			}
+			delta := attributeUsage([]v2.DailyUsage{{Date: day.Date, Usage: metav1.Duration{Duration: time.Hour}}}, "cc-2", "")
+
+			merged := MergeDailyUsages(delta, []v2.DailyUsage{attributeRemainder(mcpUsage, day)}, time.UTC)
+			Expect(merged[0].Usage.Duration).Should(Equal(11 * time.Hour))
+			Expect(ChargingTargetUsages(mcpUsage, merged[0])).Should(Equal([]v2.ChargingTargetUsage{
+				{ChargingTarget: "cc-1", Usage: metav1.Duration{Duration: 10 * time.Hour}},
+				{ChargingTarget: "cc-2", Usage: metav1.Duration{Duration: time.Hour}},
+			}))
+		})
+
+		It("should attribute the usage, which was merged without a charging target", func() {
+			mcpUsage := &v2.MCPUsage{
+				Status: v2.MCPUsageStatus{
+					UsageOperator: v2.UsageOperatorStatus{
+						ChargingTargetHistory: []v2.ChargingTargetAssignment{{ChargingTarget: "cc-1", EffectiveFrom: created}},
+					},
+				},
+			}
+			day := v2.DailyUsage{
+				Date:             metav1.NewTime(time.Date(2025, 7, 14, 0, 0, 0, 0, time.UTC)),
+				Usage:            metav1.Duration{Duration: 11 * time.Hour},
+				NonBillableUsage: metav1.Duration{Duration: 2 * time.Hour},
+				ChargingTargets:  []v2.ChargingTargetUsage{{ChargingTarget: "cc-1", Usage: metav1.Duration{Duration: time.Hour}}},
+			}
+
+			Expect(ChargingTargetUsages(mcpUsage, day)).Should(Equal([]v2.ChargingTargetUsage{
+				{ChargingTarget: "cc-1", Usage: metav1.Duration{Duration: 11 * time.Hour}, NonBillableUsage: metav1.Duration{Duration: 2 * time.Hour}},
+			}))
+		})
+
+		It("should close a day once it was captured until its end", func() {
+			berlin, err := time.LoadLocation("Europe/Berlin")
+			Expect(err).ShouldNot(HaveOccurred())
+			mcpUsage := &v2.MCPUsage{}
+			mcpUsage.Status.UsageOperator.BillingTimezone = "Europe/Berlin"
+			day := v2.DailyUsage{Date: metav1.NewTime(time.Date(2025, 7, 14, 0, 0, 0, 0, berlin))}
+			now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+
+			mcpUsage.Status.UsageOperator.LastUsageCaptured = metav1.NewTime(time.Date(2025, 7, 14, 21, 30, 0, 0, time.UTC))
+			Expect(IsDayClosed(mcpUsage, day, time.UTC, now)).Should(BeFalse())
+
+			mcpUsage.Status.UsageOperator.LastUsageCaptured = metav1.NewTime(time.Date(2025, 7, 14, 22, 0, 0, 0, time.UTC))
+			Expect(IsDayClosed(mcpUsage, day, time.UTC, now)).Should(BeTrue())
+		})
+
+		It("should close the day of the deletion once it has ended", func() {
+			deletedAt := metav1.NewTime(time.Date(2025, 7, 14, 10, 0, 0, 0, time.UTC))
+			mcpUsage := &v2.MCPUsage{}
+			mcpUsage.Status.UsageOperator.LastUsageCaptured = deletedAt
+			mcpUsage.Status.UsageOperator.MCPDeletedAt = deletedAt
+			day := v2.DailyUsage{Date: metav1.NewTime(time.Date(2025, 7, 14, 0, 0, 0, 0, time.UTC))}
+
+			Expect(IsDayClosed(mcpUsage, day, time.UTC, deletedAt.Add(time.Hour))).Should(BeFalse())
+			Expect(IsDayClosed(mcpUsage, day, time.UTC, time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC))).Should(BeTrue())
+		})
+	})
+	Context("Monthly rollup", func() {
+		day := func(month time.Month, d int, usage time.Duration) v2.DailyUsage {
+			return v2.DailyUsage{
+				Date:  metav1.NewTime(time.Date(2025, month, d, 0, 0, 0, 0, time.UTC)),
+				Usage: metav1.Duration{Duration: usage},
+			}
+		}
+		created := metav1.NewTime(time.Date(2025, 6, 20, 0, 0, 0, 0, time.UTC))
+		var mcpUsage *v2.MCPUsage
+
+		BeforeEach(func() {
+			mcpUsage = &v2.MCPUsage{
+				ObjectMeta: metav1.ObjectMeta{Name: "usage"},
+				Status: v2.MCPUsageStatus{
+					UsageOperator: v2.UsageOperatorStatus{
+						ChargingTarget: "cc-3",
+						ChargingTargetHistory: []v2.ChargingTargetAssignment{
+							{ChargingTarget: "cc-1", EffectiveFrom: created},
+							{ChargingTarget: "cc-2", EffectiveFrom: metav1.NewTime(time.Date(2025, 6, 25, 0, 0, 0, 0, time.UTC))},
+							{ChargingTarget: "cc-3", EffectiveFrom: metav1.NewTime(time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC))},
+						},
+						Usage:             []v2.DailyUsage{day(7, 2, 24*time.Hour), day(6, 30, 24*time.Hour), day(7, 1, 24*time.Hour)},
+						LastUsageCaptured: metav1.NewTime(time.Date(2025, 7, 3, 0, 0, 0, 0, time.UTC)),
+					},
+				},
+			}
+		})
+
+		It("should return the months of the daily usage", func() {
+			Expect(usageMonths(mcpUsage, time.UTC)).Should(Equal([]string{"2025-06", "2025-07"}))
+			Expect(MonthlyName(mcpUsage.Name, "2025-06")).Should(Equal("usage-2025-06"))
+		})
+
+		It("should roll up the days of a month split by charging target", func() {
+			rollup := rollupMonth(v2.MCPUsageMonthlyStatus{}, mcpUsage, "2025-07", time.UTC)
+
+			Expect(rollup.BillingTimezone).Should(Equal("UTC"))
+			Expect(rollup.Usage.Duration).Should(Equal(48 * time.Hour))
+			Expect(rollup.DailyUsage).Should(HaveLen(2))
+			Expect(rollup.DailyUsage[0].Date.Day()).Should(Equal(1))
+			Expect(rollup.ChargingTargets).Should(Equal([]v2.ChargingTargetUsage{
+				{ChargingTarget: "cc-2", Usage: metav1.Duration{Duration: 24 * time.Hour}},
+				{ChargingTarget: "cc-3", Usage: metav1.Duration{Duration: 24 * time.Hour}},
+			}))
+			// the assignment, which was active at the start of the month, and the ones within it
+			Expect(rollup.ChargingTargetHistory).Should(Equal(mcpUsage.Status.UsageOperator.ChargingTargetHistory[1:]))
+		})
+
+		It("should keep the days of the previous rollup, which were pruned", func() {
+			previous := rollupMonth(v2.MCPUsageMonthlyStatus{}, mcpUsage, "2025-07", time.UTC)
+			mcpUsage.Status.UsageOperator.Usage = []v2.DailyUsage{day(7, 2, 12*time.Hour)}
+
+			rollup := rollupMonth(previous, mcpUsage, "2025-07", time.UTC)
+			Expect(rollup.DailyUsage).Should(HaveLen(2))
+			Expect(rollup.Usage.Duration).Should(Equal(36 * time.Hour))
+		})
+
+		It("should close a month once it was captured until its end or the mcp was deleted", func() {
+			now := time.Date(2025, 7, 3, 0, 0, 0, 0, time.UTC)
+			Expect(isMonthClosed(mcpUsage, "2025-06", time.UTC, now)).Should(BeTrue())
+			Expect(isMonthClosed(mcpUsage, "2025-07", time.UTC, now)).Should(BeFalse())
+
+			mcpUsage.Status.UsageOperator.MCPDeletedAt = mcpUsage.Status.UsageOperator.LastUsageCaptured
+			Expect(isMonthClosed(mcpUsage, "2025-07", time.UTC, now)).Should(BeFalse())
+			Expect(isMonthClosed(mcpUsage, "2025-07", time.UTC, time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC))).Should(BeTrue())
+		})
+	})
+	Context("Report acknowledgement", func() {
+		var mcpUsage *v2.MCPUsage
+		date := metav1.NewTime(time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC))
+
+		BeforeEach(func() {
+			mcpUsage = &v2.MCPUsage{}
+			mcpUsage.Status.UsageOperator.Usage = []v2.DailyUsage{{Date: date, Usage: metav1.Duration{Duration: 12 * time.Hour}}}
+		})
+
+		It("should change the content hash only with the content of the day", func() {
+			day := mcpUsage.Status.UsageOperator.Usage[0]
+			hash := contentHash(day)
+			Expect(hash).ShouldNot(BeEmpty())
+
+			day.ContentHash = hash
+			day.Date = metav1.NewTime(date.In(time.FixedZone("UTC+2", 2*60*60)))
+			Expect(contentHash(day)).Should(Equal(hash))
+
+			day.Usage.Duration += time.Hour
+			Expect(contentHash(day)).ShouldNot(Equal(hash))
+		})
+
+		It("should supersede reports of days, which changed after they were reported", func() {
+			Expect(setContentHashes(mcpUsage)).Should(BeEmpty())
+			mcpUsage.Status.DailyUsageReport = []v2.DailyUsageReport{
+				{Date: date, Status: v2.ReportStatusReported, ContentHash: mcpUsage.Status.UsageOperator.Usage[0].ContentHash},
+			}
+			Expect(isAcknowledged(mcpUsage, mcpUsage.Status.UsageOperator.Usage[0])).Should(BeTrue())
+
+			mcpUsage.Status.UsageOperator.Usage[0].Usage.Duration += time.Hour
+			Expect(isAcknowledged(mcpUsage, mcpUsage.Status.UsageOperator.Usage[0])).Should(BeFalse())
+			Expect(setContentHashes(mcpUsage)).Should(Equal([]string{"2025-07-01T00:00:00Z"}))
+			Expect(mcpUsage.Status.DailyUsageReport[0].Status).Should(Equal(v2.ReportStatusSuperseded))
+			Expect(mcpUsage.Status.UsageOperator.Usage[0].ContentHash).ShouldNot(Equal(mcpUsage.Status.DailyUsageReport[0].ContentHash))
+		})
+
+		It("should only accept reported days as acknowledged", func() {
+			day := mcpUsage.Status.UsageOperator.Usage[0]
+			Expect(isAcknowledged(mcpUsage, day)).Should(BeFalse())
+
+			mcpUsage.Status.DailyUsageReport = []v2.DailyUsageReport{{Date: date, Status: v2.ReportStatusFailed}}
+			Expect(isAcknowledged(mcpUsage, day)).Should(BeFalse())
+
+			// reports without a content hash were written before the hash existed
+			mcpUsage.Status.DailyUsageReport[0].Status = v2.ReportStatusReported
+			Expect(isAcknowledged(mcpUsage, day)).Should(BeTrue())
+		})
+	})
 	Context("ObjectKey Generation", func() {
 		It("should generate the same objectkey with the same input", func() {
 			project := "Testproject"
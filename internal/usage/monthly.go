@@ -0,0 +1,102 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+)
+
+// openMonths returns the months of the MCPUsageMonthly resources, which are not final yet, by the name of their
+// MCPUsage.
+func (u *UsageTracker) openMonths(ctx context.Context) (map[string][]string, error) {
+	var monthlies v2.MCPUsageMonthlyList
+	if err := u.client.List(ctx, &monthlies); err != nil {
+		return nil, fmt.Errorf("error when getting list of monthly mcp usages: %w", err)
+	}
+
+	open := map[string][]string{}
+	for _, monthly := range monthlies.Items {
+		if !monthly.Status.Final {
+			open[monthly.Spec.MCPUsage] = append(open[monthly.Spec.MCPUsage], monthly.Spec.Month)
+		}
+	}
+	return open, nil
+}
+
+// rollupMonths writes the usage of the MCPUsage to an MCPUsageMonthly per month. These are the months of its daily
+// usage and the given open months, whose daily usage may have been pruned already. A month, which is closed, is marked
+// final and never updated again.
+func (u *UsageTracker) rollupMonths(ctx context.Context, log logr.Logger, mcpUsage *v2.MCPUsage, openMonths []string, now time.Time) error {
+	months := usageMonths(mcpUsage, u.billingLocation)
+	for _, month := range openMonths {
+		if !slices.Contains(months, month) {
+			months = append(months, month)
+		}
+	}
+
+	var errs error
+	for _, month := range months {
+		if err := u.rollupMonth(ctx, log, mcpUsage, month, now); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+	return errs
+}
+
+func (u *UsageTracker) rollupMonth(ctx context.Context, log logr.Logger, mcpUsage *v2.MCPUsage, month string, now time.Time) error {
+	monthly := &v2.MCPUsageMonthly{}
+	err := u.client.Get(ctx, client.ObjectKey{Name: MonthlyName(mcpUsage.Name, month)}, monthly)
+	switch {
+	case k8serrors.IsNotFound(err):
+		monthly = &v2.MCPUsageMonthly{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   MonthlyName(mcpUsage.Name, month),
+				Labels: map[string]string{v2.MonthLabel: month},
+			},
+			Spec: v2.MCPUsageMonthlySpec{
+				Project:   mcpUsage.Spec.Project,
+				Workspace: mcpUsage.Spec.Workspace,
+				MCP:       mcpUsage.Spec.MCP,
+				MCPUsage:  mcpUsage.Name,
+				Month:     month,
+			},
+		}
+		if err := u.client.Create(ctx, monthly); err != nil {
+			return fmt.Errorf("failed to create monthly usage %s: %w", monthly.Name, err)
+		}
+	case err != nil:
+		return fmt.Errorf("failed to get monthly usage %s: %w", MonthlyName(mcpUsage.Name, month), err)
+	case monthly.Status.Final:
+		return nil
+	}
+
+	status := rollupMonth(monthly.Status, mcpUsage, month, u.billingLocation)
+	if isMonthClosed(mcpUsage, month, u.billingLocation, now) {
+		status.Final = true
+		status.FinalizedAt = metav1.NewTime(now)
+	}
+	if equality.Semantic.DeepEqual(status, monthly.Status) {
+		return nil
+	}
+
+	monthly.Status = status
+	if err := u.client.Status().Update(ctx, monthly); err != nil {
+		return fmt.Errorf("failed to update monthly usage %s: %w", monthly.Name, err)
+	}
+	if status.Final {
+		log.Info("monthly usage is final", "mcpUsage", mcpUsage.Name, "month", month, "usage", status.Usage.Duration)
+		u.event(mcpUsage, corev1.EventTypeNormal, "MonthFinalized", "Rollup", "usage of %s is final in MCPUsageMonthly %s: %s", month, monthly.Name, status.Usage.Duration)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+)
+
+const (
+	reasonResolved         = "Resolved"
+	reasonRegistered       = "Registered"
+	reasonRuleMatched      = "RuleMatched"
+	reasonNotSpecified     = "NotSpecified"
+	reasonResolutionFailed = "ResolutionFailed"
+	reasonInvalidSplit     = "InvalidSplit"
+	reasonCaptured         = "Captured"
+	reasonCaptureFailed    = "CaptureFailed"
+	reasonFinal            = "Final"
+	reasonExists           = "Exists"
+	reasonDeleted          = "Deleted"
+	reasonOrphaned         = "Orphaned"
+)
+
+func condition(conditionType string, status metav1.ConditionStatus, reason, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:    conditionType,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// setConditions sets the given conditions and the observed generation in the status of the MCPUsage.
+func setConditions(mcpUsage *v2.MCPUsage, conditions ...metav1.Condition) {
+	status := &mcpUsage.Status.UsageOperator
+	status.ObservedGeneration = mcpUsage.Generation
+	for _, c := range conditions {
+		c.ObservedGeneration = mcpUsage.Generation
+		meta.SetStatusCondition(&status.Conditions, c)
+	}
+}
+
+// updateStatus writes the status of the MCPUsage together with the given conditions. As the status also contains the
+// reports of the metering operator, it is only written with the resource version it was read with, so reports which
+// were written in the meantime lead to a conflict instead of being overwritten. The content hashes of the days are
+// updated before, and reports of days, which changed since they were reported, are marked as superseded.
+func (u *UsageTracker) updateStatus(ctx context.Context, mcpUsage *v2.MCPUsage, conditions ...metav1.Condition) error {
+	setConditions(mcpUsage, conditions...)
+	superseded := setContentHashes(mcpUsage)
+	if err := u.client.Status().Update(ctx, mcpUsage); err != nil {
+		return err
+	}
+	if len(superseded) > 0 {
+		u.event(mcpUsage, corev1.EventTypeWarning, "ReportSuperseded", "VerifyReports", "usage of reported days changed, they need to be reported again: %s", strings.Join(superseded, ", "))
+	}
+	return nil
+}
+
+// updateMetadata writes the metadata of the MCPUsage. The changes to the status are kept, as they are not persisted by
+// an update of the resource itself.
+func (u *UsageTracker) updateMetadata(ctx context.Context, mcpUsage *v2.MCPUsage) error {
+	status := mcpUsage.Status.DeepCopy()
+	if err := u.client.Update(ctx, mcpUsage); err != nil {
+		return err
+	}
+	mcpUsage.Status = *status
+	return nil
+}
+
+// createWithStatus creates the MCPUsage and writes its status afterwards, as the status is not persisted on creation.
+func (u *UsageTracker) createWithStatus(ctx context.Context, mcpUsage *v2.MCPUsage, conditions ...metav1.Condition) error {
+	status := mcpUsage.Status.DeepCopy()
+	if err := u.client.Create(ctx, mcpUsage); err != nil {
+		return err
+	}
+	mcpUsage.Status = *status
+	return u.updateStatus(ctx, mcpUsage, conditions...)
+}
+
+// reportCaptureFailure marks the usage of the MCPUsage as not current. Errors are only logged, as the capture itself
+// already failed.
+func (u *UsageTracker) reportCaptureFailure(ctx context.Context, log logr.Logger, name string, captureErr error) {
+	var mcpUsage v2.MCPUsage
+	if err := u.client.Get(ctx, client.ObjectKey{Name: name}, &mcpUsage); err != nil {
+		log.Error(err, "error when getting MCPUsage to report the failed capture", "mcpUsage", name)
+		return
+	}
+	err := u.updateStatus(ctx, &mcpUsage, condition(v2.ConditionUsageCurrent, metav1.ConditionFalse, reasonCaptureFailed, captureErr.Error()))
+	if err != nil {
+		log.Error(err, "error when reporting the failed capture", "mcpUsage", name)
+	}
+}
+
+// presenceCondition returns the MCPPresent condition for the current incarnation of the MCP.
+func presenceCondition(mcpUsage *v2.MCPUsage) metav1.Condition {
+	if mcpUsage.Status.UsageOperator.MCPDeletedAt.IsZero() {
+		return condition(v2.ConditionMCPPresent, metav1.ConditionTrue, reasonExists, "")
+	}
+	return condition(v2.ConditionMCPPresent, metav1.ConditionFalse, reasonDeleted,
+		fmt.Sprintf("mcp was deleted at %s", mcpUsage.Status.UsageOperator.MCPDeletedAt.UTC().Format("2006-01-02T15:04:05Z")))
+}
@@ -12,7 +12,7 @@ import (
 	corev1alpha1 "github.com/openmcp-project/mcp-operator/api/core/v1alpha1"
 	pwcorev1alpha1 "github.com/openmcp-project/project-workspace-operator/api/core/v1alpha1"
 
-	v1 "github.com/openmcp-project/usage-operator/api/usage/v1"
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
 
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
@@ -47,7 +47,7 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 	err = pwcorev1alpha1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
-	err = v1.AddToScheme(scheme.Scheme)
+	err = v2.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
 
 	// +kubebuilder:scaffold:scheme
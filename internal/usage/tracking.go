@@ -3,32 +3,174 @@ package usage
 import (
 	"context"
 	"errors"
+	"slices"
+	"strings"
 	"time"
 
 	"fmt"
 
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/go-logr/logr"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
-	v1 "github.com/openmcp-project/usage-operator/api/usage/v1"
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+	"github.com/openmcp-project/usage-operator/internal/cloudevents"
+	"github.com/openmcp-project/usage-operator/internal/config"
 	"github.com/openmcp-project/usage-operator/internal/helper"
+	"github.com/openmcp-project/usage-operator/internal/metrics"
 )
 
 type UsageTracker struct {
 	client client.Client
+
+	billingLocation *time.Location
+	billablePhases  []string
+	granularity     time.Duration
+	retention       time.Duration
+	gcDryRun        bool
+	requireAck      bool
+	maxAge          time.Duration
+	keyByUID        bool
+
+	chargingTargetResolver *helper.ChargingTargetResolver
+	recorder               events.EventRecorder
+	publisher              *cloudevents.Publisher
 }
 
 func NewUsageTracker(client client.Client) (*UsageTracker, error) {
 	return &UsageTracker{
-		client: client,
+		client:          client,
+		billingLocation: time.UTC,
+		billablePhases:  config.DefaultBillablePhases,
+		granularity:     config.DefaultGranularity,
+		retention:       config.DefaultRetention,
+
+		chargingTargetResolver: helper.DefaultChargingTargetResolver(),
 	}, nil
 }
 
+// WithBillingLocation sets the timezone which determines the day boundaries, if the MCPUsage has no billing
+// timezone of its own.
+func (u *UsageTracker) WithBillingLocation(loc *time.Location) *UsageTracker {
+	u.billingLocation = loc
+	return u
+}
+
+// WithBillablePhases sets the phases of an MCP, in which its usage is billable. Time spent in other phases is
+// captured as non-billable usage.
+func (u *UsageTracker) WithBillablePhases(phases []string) *UsageTracker {
+	u.billablePhases = phases
+	return u
+}
+
+// WithGranularity sets the precision with which usage is captured. All captured timestamps are truncated to it.
+func (u *UsageTracker) WithGranularity(granularity time.Duration) *UsageTracker {
+	u.granularity = granularity
+	return u
+}
+
+// WithRetention sets the duration for which DailyUsage entries are kept, if not overridden by the MCPUsage itself.
+func (u *UsageTracker) WithRetention(retention time.Duration) *UsageTracker {
+	u.retention = retention
+	return u
+}
+
+// WithGarbageCollectionDryRun makes the garbage collection only log the entries it would prune.
+func (u *UsageTracker) WithGarbageCollectionDryRun(dryRun bool) *UsageTracker {
+	u.gcDryRun = dryRun
+	return u
+}
+
+// WithRequireAcknowledgement makes the garbage collection keep days, which the metering operator hasn't acknowledged
+// with a report of their current content, beyond the retention.
+func (u *UsageTracker) WithRequireAcknowledgement(requireAck bool) *UsageTracker {
+	u.requireAck = requireAck
+	return u
+}
+
+// WithMaxAge sets the age, after which unacknowledged DailyUsage entries are pruned anyway. Zero keeps them forever.
+func (u *UsageTracker) WithMaxAge(maxAge time.Duration) *UsageTracker {
+	u.maxAge = maxAge
+	return u
+}
+
+// WithChargingTargetResolver sets the resolver, which determines the charging target of an MCP.
+func (u *UsageTracker) WithChargingTargetResolver(resolver *helper.ChargingTargetResolver) *UsageTracker {
+	u.chargingTargetResolver = resolver
+	return u
+}
+
+// WithEventRecorder sets the recorder, with which events about MCPUsages are recorded.
+func (u *UsageTracker) WithEventRecorder(recorder events.EventRecorder) *UsageTracker {
+	u.recorder = recorder
+	return u
+}
+
+// WithPublisher sets the publisher, with which CloudEvents about the creation and deletion of MCPs and their charging
+// targets are published.
+func (u *UsageTracker) WithPublisher(publisher *cloudevents.Publisher) *UsageTracker {
+	u.publisher = publisher
+	return u
+}
+
+// WithKeyByUID names new MCPUsages after the uid of their MCP instead of project, workspace and mcp name, so every
+// incarnation of an MCP gets its own MCPUsage.
+func (u *UsageTracker) WithKeyByUID(keyByUID bool) *UsageTracker {
+	u.keyByUID = keyByUID
+	return u
+}
+
+// now returns the current time truncated to the granularity of the tracker. As the usage is calculated between
+// these timestamps, the captured usage always sums up to the time elapsed between the stored timestamps.
+func (u *UsageTracker) now() time.Time {
+	return time.Now().UTC().Truncate(u.granularity)
+}
+
+// isBillable returns whether the time an MCP spends in the given phase is billable.
+func (u *UsageTracker) isBillable(phase string) bool {
+	return phase == "" || slices.Contains(u.billablePhases, phase)
+}
+
+// captureUsage adds the time between the last capture and until to the usage of the MCPUsage. Depending on the
+// phase of the MCP, the time is captured as billable or non-billable usage. The captured usage is returned, so it can be
+// recorded in the metrics once it is stored.
+func (u *UsageTracker) captureUsage(log logr.Logger, mcpUsage *v2.MCPUsage, until time.Time) []v2.DailyUsage {
+	if !until.After(mcpUsage.Status.UsageOperator.LastUsageCaptured.Time) {
+		return nil
+	}
+
+	loc, err := getBillingLocation(mcpUsage, u.billingLocation)
+	if err != nil {
+		log.Error(err, "invalid billing timezone, falling back to the default billing timezone", "billingTimezone", u.billingLocation)
+	}
+
+	usages := calculateUsage(until, mcpUsage.Status.UsageOperator.LastUsageCaptured.Time, loc)
+	if !u.isBillable(mcpUsage.Status.UsageOperator.MCPPhase) {
+		usages = asNonBillable(usages)
+	}
+	// the charging target is captured up to every change, so the whole time belongs to the current one
+	if mcpUsage.Status.UsageOperator.ChargingTarget != "" {
+		usages = attributeUsage(usages, mcpUsage.Status.UsageOperator.ChargingTarget, mcpUsage.Status.UsageOperator.ChargingTargetType)
+		// the usage of a day, which isn't attributed yet, would be lost once the captured usage is merged into it
+		for i := range mcpUsage.Status.UsageOperator.Usage {
+			mcpUsage.Status.UsageOperator.Usage[i] = attributeRemainder(mcpUsage, mcpUsage.Status.UsageOperator.Usage[i])
+		}
+	}
+
+	mcpUsage.Status.UsageOperator.Usage = MergeDailyUsages(usages, mcpUsage.Status.UsageOperator.Usage, loc)
+	mcpUsage.Status.UsageOperator.LastUsageCaptured = metav1.NewTime(until)
+	return usages
+}
+
 func (u *UsageTracker) initLogger(ctx context.Context, name, project, workspace, mcp_name string) logr.Logger {
 	log := logf.FromContext(ctx)
 
@@ -39,16 +181,86 @@ func (u *UsageTracker) initLogger(ctx context.Context, name, project, workspace,
 	)
 }
 
-func (u *UsageTracker) CreateOrUpdateEvent(ctx context.Context, project string, workspace string, mcp_name string) error {
+// resolveObjectKey returns the key of the MCPUsage, which tracks the MCP with the given uid. MCPUsages are found by
+// their mcp uid label first. Otherwise they are keyed by the uid, if enabled, or by project, workspace and mcp name.
+// If keyed by uid, MCPUsages which were keyed by name before and are not yet bound to an uid are still used, so
+// existing records are migrated as soon as their MCP is reconciled.
+func (u *UsageTracker) resolveObjectKey(ctx context.Context, project, workspace, mcp_name string, uid types.UID) (client.ObjectKey, error) {
+	nameKey, err := GetObjectKey(project, workspace, mcp_name)
+	if err != nil || uid == "" {
+		return nameKey, err
+	}
+
+	var mcpUsages v2.MCPUsageList
+	if err := u.client.List(ctx, &mcpUsages, client.MatchingLabels{v2.MCPUIDLabel: string(uid)}); err != nil {
+		return client.ObjectKey{}, fmt.Errorf("error listing MCPUsages by mcp uid: %w", err)
+	}
+	if len(mcpUsages.Items) > 0 {
+		return client.ObjectKeyFromObject(&mcpUsages.Items[0]), nil
+	}
+
+	if !u.keyByUID {
+		return nameKey, nil
+	}
+
+	var legacy v2.MCPUsage
+	err = u.client.Get(ctx, nameKey, &legacy)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return client.ObjectKey{}, fmt.Errorf("error getting MCPUsage keyed by name: %w", err)
+	}
+	if err == nil && legacy.Status.UsageOperator.MCPDeletedAt.IsZero() && legacy.Status.UsageOperator.MCPUID == "" {
+		return nameKey, nil
+	}
+
+	return client.ObjectKey{Name: string(uid)}, nil
+}
+
+// endPreviousIncarnations marks other MCPUsages of the same MCP name as deleted, as their deletion was missed if a
+// new incarnation got its own MCPUsage.
+func (u *UsageTracker) endPreviousIncarnations(ctx context.Context, log logr.Logger, project, workspace, mcp_name, current string) error {
+	var mcpUsages v2.MCPUsageList
+	if err := u.client.List(ctx, &mcpUsages); err != nil {
+		return fmt.Errorf("error listing MCPUsages: %w", err)
+	}
+
+	var errs error
+	for _, mcpUsage := range mcpUsages.Items {
+		if mcpUsage.Name == current || !mcpUsage.Status.UsageOperator.MCPDeletedAt.IsZero() ||
+			mcpUsage.Spec.Project != project || mcpUsage.Spec.Workspace != workspace || mcpUsage.Spec.MCP != mcp_name {
+			continue
+		}
+
+		log.Info("mcp was re-created without capturing the deletion of its previous incarnation", "mcpUsage", mcpUsage.Name)
+		message := fmt.Sprintf("deletion was not captured before the mcp was re-created as %s, it ended with the last usage capture", current)
+		err := u.markDeleted(ctx, log, client.ObjectKeyFromObject(&mcpUsage), mcpUsage.Status.UsageOperator.LastUsageCaptured.Time, message)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		u.event(&mcpUsage, corev1.EventTypeWarning, "DeletionMissed", "MarkDeleted", message)
+	}
+	return errs
+}
+
+func (u *UsageTracker) CreateOrUpdateEvent(ctx context.Context, project string, workspace string, mcp_name string, uid types.UID, phase string) error {
 	log := u.initLogger(ctx, "creation-update", project, workspace, mcp_name)
 
-	objectKey, err := GetObjectKey(project, workspace, mcp_name)
+	objectKey, err := u.resolveObjectKey(ctx, project, workspace, mcp_name, uid)
 	if err != nil {
 		return fmt.Errorf("error getting object key: %w", err)
 	}
 
+	created := false
+	// started is true, if a new lifecycle interval was started
+	started := false
+	// recreated describes how a new incarnation of the mcp was detected, it is recorded as event once stored
+	recreated := ""
+	// the start of the interval is taken once, so a retry publishes the same mcp.created event with the same id
+	now := metav1.NewTime(u.now())
+	var mcpUsage v2.MCPUsage
 	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		var mcpUsage v1.MCPUsage
+		started, recreated = false, ""
+		mcpUsage = v2.MCPUsage{}
 		err = u.client.Get(ctx, objectKey, &mcpUsage)
 		if err != nil && !k8serrors.IsNotFound(err) {
 			return fmt.Errorf("error at getting MCPUsage resource for %v: %w", mcp_name, err)
@@ -59,45 +271,89 @@ func (u *UsageTracker) CreateOrUpdateEvent(ctx context.Context, project string,
 		if k8serrors.IsNotFound(err) { // element does not exist, we need to create it
 			log.Info("no mcp usage element found. Creating a new one", "objectKey", objectKey)
 
-			now := metav1.NewTime(time.Now().UTC())
-			mcpUsage = v1.MCPUsage{
+			mcpUsage = v2.MCPUsage{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      objectKey.Name,
 					Namespace: objectKey.Namespace,
 				},
-				Spec: v1.MCPUsageSpec{
-					Project:           project,
-					Workspace:         workspace,
-					MCP:               mcp_name,
-					Usage:             []v1.DailyUsage{},
-					LastUsageCaptured: now,
-					MCPCreatedAt:      now,
+				Spec: v2.MCPUsageSpec{
+					Project:   project,
+					Workspace: workspace,
+					MCP:       mcp_name,
 				},
 			}
+			mcpUsage.Status.UsageOperator.MCPPhase = normalizePhase(phase)
+			setMCPUID(&mcpUsage, uid)
+			startInterval(&mcpUsage, now, uid)
 
-			err = u.client.Create(ctx, &mcpUsage)
+			err = u.publish(cloudevents.TypeMCPCreated, &mcpUsage, mcpUsage.Status.UsageOperator.MCPCreatedAt.Time, mcpData(&mcpUsage))
+			if err != nil {
+				return err
+			}
+			err = u.createWithStatus(ctx, &mcpUsage, presenceCondition(&mcpUsage))
 			if err != nil {
 				return fmt.Errorf("error when creating MCPUsage resource: %w", err)
 			}
-		} else {
-			// check if mcpUsage element wants to be deleted
-			if !mcpUsage.Spec.MCPDeletedAt.IsZero() {
-				log.Info("mcp was deleted in the past, update last usage captured and proceed")
-				// MCP was deleted, now created with the same name, update lastUsageCapture
-				mcpUsage.Spec.LastUsageCaptured = metav1.NewTime(time.Now().UTC())
-				err = u.client.Update(ctx, &mcpUsage)
-				if err != nil {
-					if k8serrors.IsConflict(err) {
-						log.Info("conflict detected when updating resource", "MCPUsage", mcpUsage.Name)
-						return err
-					}
-					return fmt.Errorf("error when updating status for MCPUsage resource: %w", err)
+			created, started = true, true
+			return nil
+		}
+
+		status := &mcpUsage.Status.UsageOperator
+		switch {
+		case status.MCPCreatedAt.IsZero():
+			log.Info("mcp usage element has no status yet, start the first lifecycle interval")
+			status.MCPPhase = normalizePhase(phase)
+			startInterval(&mcpUsage, now, uid)
+			started = true
+		case !status.MCPDeletedAt.IsZero():
+			log.Info("mcp was deleted in the past, start a new lifecycle interval")
+			// MCP was deleted, now created with the same name, the time in between is not billed
+			startInterval(&mcpUsage, now, uid)
+			status.Message = ""
+			started, recreated = true, "mcp was re-created, started a new lifecycle interval"
+		case uid != "" && status.MCPUID == "":
+			log.Info("adopting mcp usage element, which was created before the mcp uid was tracked", "uid", uid)
+			if last := len(status.Lifecycle) - 1; last >= 0 && status.Lifecycle[last].UID == "" {
+				status.Lifecycle[last].UID = uid
+			}
+		case uid != "" && status.MCPUID != uid:
+			// the deletion of the previous incarnation was missed, it must not be merged with the new one
+			log.Info("mcp was re-created without capturing its deletion, start a new lifecycle interval", "previousUID", status.MCPUID, "uid", uid)
+			endInterval(&mcpUsage, status.LastUsageCaptured)
+			startInterval(&mcpUsage, now, uid)
+			status.Message = fmt.Sprintf("deletion of the previous incarnation %s was not captured, it ended with the last usage capture", status.Lifecycle[len(status.Lifecycle)-2].UID)
+			started, recreated = true, status.Message
+		case meta.FindStatusCondition(status.Conditions, v2.ConditionMCPPresent) == nil:
+			log.Info("mcp usage element has no conditions yet")
+		default:
+			// event was fired one time to much? do nothing and return later
+			log.Info("create or update event was fired again but MCPUsage is already valid, ignore it")
+			return nil
+		}
+
+		if setMCPUID(&mcpUsage, uid) {
+			if err := u.updateMetadata(ctx, &mcpUsage); err != nil {
+				if k8serrors.IsConflict(err) {
+					log.Info("conflict detected when updating resource", "MCPUsage", mcpUsage.Name)
+					return err
 				}
-			} else {
-				// event was fired one time to much? do nothing and return later
-				log.Info("create or update event was fired again but MCPUsage is already valid, ignore it")
+				return fmt.Errorf("error when updating labels of MCPUsage resource: %w", err)
 			}
+		}
 
+		if started {
+			err = u.publish(cloudevents.TypeMCPCreated, &mcpUsage, mcpUsage.Status.UsageOperator.MCPCreatedAt.Time, mcpData(&mcpUsage))
+			if err != nil {
+				return err
+			}
+		}
+		err = u.updateStatus(ctx, &mcpUsage, presenceCondition(&mcpUsage))
+		if err != nil {
+			if k8serrors.IsConflict(err) {
+				log.Info("conflict detected when updating resource", "MCPUsage", mcpUsage.Name)
+				return err
+			}
+			return fmt.Errorf("error when updating status for MCPUsage resource: %w", err)
 		}
 
 		return nil
@@ -107,9 +363,28 @@ func (u *UsageTracker) CreateOrUpdateEvent(ctx context.Context, project string,
 		return fmt.Errorf("error when updating mcp usage resource: %w", err)
 	}
 
+	switch {
+	case created:
+		u.event(&mcpUsage, corev1.EventTypeNormal, "Created", "Create", "started tracking the usage of mcp %s in workspace %s of project %s", mcp_name, workspace, project)
+	case recreated != "":
+		u.event(&mcpUsage, corev1.EventTypeNormal, "Recreated", "StartInterval", recreated)
+	}
+
+	if created && u.keyByUID {
+		err = u.endPreviousIncarnations(ctx, log, project, workspace, mcp_name, objectKey.Name)
+		if err != nil {
+			return fmt.Errorf("error when ending previous incarnations: %w", err)
+		}
+	}
+
+	err = u.UpdatePhase(ctx, project, workspace, mcp_name, uid, phase)
+	if err != nil {
+		return fmt.Errorf("error when updating phase: %w", err)
+	}
+
 	log.Info("update charging target for mcpusage element")
 	// ALWAYS: Check charging target and override it to make sure always the latest charging target is there.
-	err = u.UpdateChargingTarget(ctx, project, workspace, mcp_name)
+	err = u.UpdateChargingTarget(ctx, project, workspace, mcp_name, uid)
 	if err != nil {
 		return fmt.Errorf("error when updating charging target: %w", err)
 	}
@@ -117,35 +392,146 @@ func (u *UsageTracker) CreateOrUpdateEvent(ctx context.Context, project string,
 	return nil
 }
 
-func (u *UsageTracker) UpdateChargingTarget(ctx context.Context, project string, workspace string, mcp_name string) error {
+// UpdatePhase records a new phase of the MCP. The usage until now is captured with the previous phase, so every
+// phase is billed correctly.
+func (u *UsageTracker) UpdatePhase(ctx context.Context, project string, workspace string, mcp_name string, uid types.UID, phase string) error {
+	log := u.initLogger(ctx, "phase", project, workspace, mcp_name)
+
+	objectKey, err := u.resolveObjectKey(ctx, project, workspace, mcp_name, uid)
+	if err != nil {
+		return fmt.Errorf("error getting object key: %w", err)
+	}
+
+	phase = normalizePhase(phase)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var mcpUsage v2.MCPUsage
+		err := u.client.Get(ctx, objectKey, &mcpUsage)
+		if err != nil {
+			return fmt.Errorf("error at getting MCPUsage resource for %v: %w", mcp_name, err)
+		}
+
+		if mcpUsage.Status.UsageOperator.MCPPhase == phase {
+			return nil
+		}
+
+		log.Info("mcp phase changed", "from", mcpUsage.Status.UsageOperator.MCPPhase, "to", phase)
+		var captured []v2.DailyUsage
+		if mcpUsage.Status.UsageOperator.MCPDeletedAt.IsZero() {
+			captured = u.captureUsage(log, &mcpUsage, u.now())
+		}
+		mcpUsage.Status.UsageOperator.MCPPhase = phase
+
+		err = u.updateStatus(ctx, &mcpUsage)
+		if err != nil {
+			if k8serrors.IsConflict(err) {
+				log.Info("Conflict detected for MCPUsage, retrying...", "MCPUsageName", mcpUsage.Name)
+				return err
+			}
+			return fmt.Errorf("error at updating MCPUsage resource for %s %s %s: %w", project, workspace, mcp_name, err)
+		}
+		metrics.RecordUsage(project, workspace, captured)
+
+		return nil
+	})
+}
+
+func (u *UsageTracker) UpdateChargingTarget(ctx context.Context, project string, workspace string, mcp_name string, uid types.UID) error {
 	log := u.initLogger(ctx, "charging_target", project, workspace, mcp_name)
 
-	objectKey, err := GetObjectKey(project, workspace, mcp_name)
+	objectKey, err := u.resolveObjectKey(ctx, project, workspace, mcp_name, uid)
 	if err != nil {
 		return fmt.Errorf("error getting object key: %w", err)
 	}
 
+	// the time of the change is taken once, so a retry publishes the same charging_target.changed event with the same id
+	now := u.now()
 	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		var mcpUsage v1.MCPUsage
+		var mcpUsage v2.MCPUsage
 		err = u.client.Get(ctx, objectKey, &mcpUsage)
 		if err != nil && !k8serrors.IsNotFound(err) {
 			return fmt.Errorf("error at getting MCPUsage resource for %v: %w", mcp_name, err)
 		}
 
-		chargingTarget, chargingTargetType, err := helper.ResolveChargingTarget(ctx, u.client, project, workspace, mcp_name)
+		resolved := condition(v2.ConditionChargingTargetResolved, metav1.ConditionTrue, reasonResolved, "")
+		resolution, err := u.chargingTargetResolver.Resolution(ctx, u.client, project, workspace, mcp_name)
+		chargingTarget, chargingTargetType := resolution.ChargingTarget, resolution.ChargingTargetType
+		if resolution.Rule != "" {
+			resolved = condition(v2.ConditionChargingTargetResolved, metav1.ConditionTrue, reasonRuleMatched, fmt.Sprintf("derived by the rule %q", resolution.Rule))
+		}
+		mcpUsage.Status.UsageOperator.ChargingTargetRule = resolution.Rule
 		if err != nil {
 			log.Error(err, fmt.Sprintf("error when resolving charging target %s %s %s", project, workspace, mcp_name))
-			mcpUsage.Spec.Message = "error when resolving charging target"
-			chargingTarget = "missing"
+			mcpUsage.Status.UsageOperator.Message = "error when resolving charging target"
+			chargingTarget = missingChargingTarget
+			resolved = condition(v2.ConditionChargingTargetResolved, metav1.ConditionFalse, reasonResolutionFailed, err.Error())
 		}
 		if chargingTarget == "" {
-			chargingTarget = "missing"
-			mcpUsage.Spec.Message = "no charging target specified"
+			chargingTarget = missingChargingTarget
+			mcpUsage.Status.UsageOperator.Message = "no charging target specified"
+			resolved = condition(v2.ConditionChargingTargetResolved, metav1.ConditionFalse, reasonNotSpecified, "no charging target specified on the project or workspace")
+		}
+		if _, err := helper.ParseChargingTargetSplit(chargingTarget); err != nil {
+			log.Error(err, "invalid weighted charging target")
+			chargingTarget = missingChargingTarget
+			mcpUsage.Status.UsageOperator.Message = "invalid weighted charging target"
+			resolved = condition(v2.ConditionChargingTargetResolved, metav1.ConditionFalse, reasonInvalidSplit, err.Error())
+		}
+		// a failed resolution is only recorded as event, when it fails for a new reason
+		previous := meta.FindStatusCondition(mcpUsage.Status.UsageOperator.Conditions, v2.ConditionChargingTargetResolved)
+		resolutionFailed := resolved.Status == metav1.ConditionFalse &&
+			(previous == nil || previous.Status != metav1.ConditionFalse || previous.Reason != resolved.Reason)
+		conditions := []metav1.Condition{resolved}
+		if u.chargingTargetResolver.Validates() {
+			var valid metav1.Condition
+			chargingTarget, chargingTargetType, valid, err = u.validateChargingTarget(ctx, log, &mcpUsage, chargingTarget, chargingTargetType, resolved.Status == metav1.ConditionTrue)
+			if err != nil {
+				return err
+			}
+			conditions = append(conditions, valid)
+		} else if resolved.Status != metav1.ConditionTrue && u.chargingTargetResolver.Default() != "" {
+			chargingTarget, chargingTargetType = u.chargingTargetResolver.Default(), ""
+			mcpUsage.Status.UsageOperator.Message = "using the default charging target"
+		}
+		current := mcpUsage.Status.UsageOperator
+		var captured []v2.DailyUsage
+		if (current.ChargingTarget != chargingTarget || current.ChargingTargetType != chargingTargetType) && current.MCPDeletedAt.IsZero() {
+			// the usage until now still belongs to the previous charging target
+			captured = u.captureUsage(log, &mcpUsage, now)
+		}
+		changed := setChargingTarget(&mcpUsage, chargingTarget, chargingTargetType, now)
+		if changed {
+			log.Info("charging target changed", "from", current.ChargingTarget, "to", chargingTarget)
 		}
-		mcpUsage.Spec.ChargingTarget = chargingTarget
-		mcpUsage.Spec.ChargingTargetType = chargingTargetType
 
-		err = u.client.Update(ctx, &mcpUsage)
+		// the billing timezone belongs to the charging target, so it is resolved together with it
+		billingTimezone, err := helper.ResolveBillingTimezone(ctx, u.client, project, workspace)
+		if err != nil {
+			log.Error(err, "error when resolving billing timezone, keeping the previous one", "billingTimezone", mcpUsage.Status.UsageOperator.BillingTimezone)
+		} else if billingTimezone != mcpUsage.Status.UsageOperator.BillingTimezone {
+			previous := BillingLocation(&mcpUsage, u.billingLocation)
+			mcpUsage.Status.UsageOperator.BillingTimezone = billingTimezone
+			moveBillingTimezone(&mcpUsage, previous, BillingLocation(&mcpUsage, u.billingLocation))
+		}
+
+		if changed {
+			history := mcpUsage.Status.UsageOperator.ChargingTargetHistory
+			effectiveFrom := history[len(history)-1].EffectiveFrom.Time
+			err = u.publish(cloudevents.TypeChargingTargetChanged, &mcpUsage, effectiveFrom, cloudevents.ChargingTargetData{
+				Project:                project,
+				Workspace:              workspace,
+				MCP:                    mcp_name,
+				MCPUsage:               mcpUsage.Name,
+				ChargingTarget:         chargingTarget,
+				ChargingTargetType:     chargingTargetType,
+				PreviousChargingTarget: current.ChargingTarget,
+				EffectiveFrom:          effectiveFrom,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = u.updateStatus(ctx, &mcpUsage, conditions...)
 		if err != nil {
 			if k8serrors.IsConflict(err) {
 				log.Info("Conflict detected for MCPUsage, retrying...", "MCPUsageName", mcpUsage.Name)
@@ -153,37 +539,175 @@ func (u *UsageTracker) UpdateChargingTarget(ctx context.Context, project string,
 			}
 			return fmt.Errorf("error at updating MCPUsage status resource for %s %s %s: %w", project, workspace, mcp_name, err)
 		}
+		metrics.RecordUsage(project, workspace, captured)
 
+		if resolutionFailed {
+			u.event(&mcpUsage, corev1.EventTypeWarning, "ChargingTargetResolutionFailed", "ResolveChargingTarget", resolved.Message)
+		}
+		switch {
+		case changed && current.ChargingTarget == "":
+			u.event(&mcpUsage, corev1.EventTypeNormal, "ChargingTargetSet", "ResolveChargingTarget", "charging target set to %q", chargingTarget)
+		case changed:
+			u.event(&mcpUsage, corev1.EventTypeNormal, "ChargingTargetChanged", "ResolveChargingTarget", "charging target changed from %q to %q", current.ChargingTarget, chargingTarget)
+		}
 		return nil
 	})
 
 	return err
 }
 
-func (u *UsageTracker) DeletionEvent(ctx context.Context, project string, workspace string, mcp_name string) error {
-	_ = u.initLogger(ctx, "deletion", project, workspace, mcp_name)
+// validateChargingTarget checks the resolved charging target against the ChargingTarget registry. It returns the
+// charging target which is used and the ChargingTargetValid condition. Invalid or missing charging targets are
+// replaced by the default charging target, if one is configured. An event is recorded, when a charging target
+// becomes invalid.
+func (u *UsageTracker) validateChargingTarget(ctx context.Context, log logr.Logger, mcpUsage *v2.MCPUsage, chargingTarget, chargingTargetType string, resolved bool) (string, string, metav1.Condition, error) {
+	now := u.now()
+	valid := condition(v2.ConditionChargingTargetValid, metav1.ConditionFalse, reasonNotSpecified, "no charging target to validate")
+	if resolved {
+		// every share of a weighted charging target has to be valid
+		validType, err := u.chargingTargetResolver.ValidateShares(ctx, u.client, chargingTarget, chargingTargetType, now)
+		var invalid *helper.InvalidChargingTargetError
+		switch {
+		case errors.As(err, &invalid):
+			valid = condition(v2.ConditionChargingTargetValid, metav1.ConditionFalse, invalid.Reason, invalid.Error())
+			previous := meta.FindStatusCondition(mcpUsage.Status.UsageOperator.Conditions, v2.ConditionChargingTargetValid)
+			if previous == nil || previous.Reason != invalid.Reason {
+				u.event(mcpUsage, corev1.EventTypeWarning, "InvalidChargingTarget", "ValidateChargingTarget", invalid.Error())
+			}
+			log.Info("charging target is invalid", "chargingTarget", chargingTarget, "reason", invalid.Reason)
+			mcpUsage.Status.UsageOperator.Message = invalid.Error()
+		case err != nil:
+			return "", "", valid, fmt.Errorf("error when validating charging target: %w", err)
+		default:
+			return chargingTarget, validType, condition(v2.ConditionChargingTargetValid, metav1.ConditionTrue, reasonRegistered, ""), nil
+		}
+	}
+
+	defaultChargingTarget := u.chargingTargetResolver.Default()
+	if defaultChargingTarget == "" {
+		return chargingTarget, chargingTargetType, valid, nil
+	}
 
-	objectKey, err := GetObjectKey(project, workspace, mcp_name)
+	defaultType, err := u.chargingTargetResolver.Validate(ctx, u.client, defaultChargingTarget, "", now)
+	if err != nil {
+		log.Error(err, "default charging target is invalid", "chargingTarget", defaultChargingTarget)
+	}
+	valid.Message += fmt.Sprintf(", using the default charging target %q", defaultChargingTarget)
+	mcpUsage.Status.UsageOperator.Message = "using the default charging target"
+	return defaultChargingTarget, defaultType, valid, nil
+}
+
+// chargingTargetExpired returns whether the charging target of the MCPUsage was valid, but isn't anymore at the given
+// time. A charging target expires by time alone, so no change of a watched resource triggers its validation.
+func (u *UsageTracker) chargingTargetExpired(ctx context.Context, mcpUsage *v2.MCPUsage, at time.Time) (bool, error) {
+	status := mcpUsage.Status.UsageOperator
+	if !u.chargingTargetResolver.Validates() || !meta.IsStatusConditionTrue(status.Conditions, v2.ConditionChargingTargetValid) {
+		return false, nil
+	}
+	_, err := u.chargingTargetResolver.ValidateShares(ctx, u.client, status.ChargingTarget, status.ChargingTargetType, at)
+	var invalid *helper.InvalidChargingTargetError
+	if errors.As(err, &invalid) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error when validating charging target of MCPUsage %s: %w", mcpUsage.Name, err)
+	}
+	return false, nil
+}
+
+// event records an event for the given object, if the tracker has an event recorder.
+func (u *UsageTracker) event(obj runtime.Object, eventType, reason, action, note string, args ...any) {
+	if u.recorder == nil {
+		return
+	}
+	u.recorder.Eventf(obj, nil, eventType, reason, action, note, args...)
+}
+
+// publish stores a CloudEvent about the MCPUsage in the outbox, if a publisher is set. It is called before the state is
+// stored, so the change is only stored together with its event. If storing the state fails, the event is published
+// again with the retry, and consumers drop the duplicate by its id.
+func (u *UsageTracker) publish(eventType string, mcpUsage *v2.MCPUsage, at time.Time, data any) error {
+	if u.publisher == nil {
+		return nil
+	}
+	if err := u.publisher.Publish(eventType, mcpUsage.Name, at, data); err != nil {
+		return fmt.Errorf("error publishing %s event of MCPUsage %s: %w", eventType, mcpUsage.Name, err)
+	}
+	return nil
+}
+
+func (u *UsageTracker) DeletionEvent(ctx context.Context, project string, workspace string, mcp_name string, uid types.UID) error {
+	return u.DeletionEventAt(ctx, project, workspace, mcp_name, uid, u.now())
+}
+
+// DeletionEventAt marks the MCPUsage as deleted at the given time, e.g. the deletion timestamp of the MCP. The usage
+// up to that time is captured first.
+func (u *UsageTracker) DeletionEventAt(ctx context.Context, project string, workspace string, mcp_name string, uid types.UID, at time.Time) error {
+	log := u.initLogger(ctx, "deletion", project, workspace, mcp_name)
+
+	objectKey, err := u.resolveObjectKey(ctx, project, workspace, mcp_name, uid)
 	if err != nil {
 		return fmt.Errorf("error getting object key: %w", err)
 	}
+	return u.markDeleted(ctx, log, objectKey, at, "")
+}
 
-	deletedAt := metav1.NewTime(time.Now().UTC())
-	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		var mcpUsage v1.MCPUsage
+// OrphanEvent marks the MCPUsage of a mcp, which was deleted without the usage-operator noticing, as deleted at the
+// given time. The message explains how the deletion was detected and is stored in the MCPUsage.
+func (u *UsageTracker) OrphanEvent(ctx context.Context, project string, workspace string, mcp_name string, uid types.UID, at time.Time, message string) error {
+	log := u.initLogger(ctx, "orphan", project, workspace, mcp_name)
+
+	objectKey, err := u.resolveObjectKey(ctx, project, workspace, mcp_name, uid)
+	if err != nil {
+		return fmt.Errorf("error getting object key: %w", err)
+	}
+	return u.markDeleted(ctx, log, objectKey, at, message)
+}
+
+// markDeleted captures the usage up to the given time and sets it as deletion time of the MCPUsage.
+// A non-empty message replaces the message of the MCPUsage.
+func (u *UsageTracker) markDeleted(ctx context.Context, log logr.Logger, objectKey client.ObjectKey, at time.Time, message string) error {
+	deletedAt := metav1.NewTime(at.UTC().Truncate(u.granularity))
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var mcpUsage v2.MCPUsage
 		// Re-fetch the latest version to avoid update conflicts
 		err := u.client.Get(ctx, objectKey, &mcpUsage)
 		if err != nil {
 			return fmt.Errorf("error getting MCPUsage resource during retry: %w", err)
 		}
-		mcpUsage.Spec.MCPDeletedAt = deletedAt
-		err = u.client.Update(ctx, &mcpUsage)
+		if !mcpUsage.Status.UsageOperator.MCPDeletedAt.IsZero() {
+			// deletion was already captured
+			return nil
+		}
+		// capture the usage since the last scheduled event, so it does not get lost
+		captured := u.captureUsage(log, &mcpUsage, deletedAt.Time)
+		endInterval(&mcpUsage, deletedAt)
+		if message != "" {
+			mcpUsage.Status.UsageOperator.Message = message
+		}
+
+		present := presenceCondition(&mcpUsage)
+		if message != "" {
+			present.Reason = reasonOrphaned
+			present.Message = message
+		}
+		err = u.publish(cloudevents.TypeMCPDeleted, &mcpUsage, deletedAt.Time, mcpData(&mcpUsage))
+		if err != nil {
+			return err
+		}
+		err = u.updateStatus(ctx, &mcpUsage, present,
+			condition(v2.ConditionUsageCurrent, metav1.ConditionTrue, reasonFinal, "usage is final, as the mcp was deleted"))
 		if err != nil {
 			if k8serrors.IsConflict(err) {
 				return err // trigger retry
 			}
 			return fmt.Errorf("error when setting deletion timestamp on MCPUsage element: %w", err)
 		}
+		metrics.RecordUsage(mcpUsage.Spec.Project, mcpUsage.Spec.Workspace, captured)
+		// deletions with a message were missed, their callers record why
+		if message == "" {
+			u.event(&mcpUsage, corev1.EventTypeNormal, "DeletionCaptured", "MarkDeleted", "usage is final, the mcp was deleted at %s", deletedAt.UTC().Format(time.RFC3339))
+		}
 		return nil
 	})
 
@@ -194,19 +718,50 @@ func (u *UsageTracker) DeletionEvent(ctx context.Context, project string, worksp
 	return nil
 }
 
-func (u *UsageTracker) ScheduledEvent(ctx context.Context) error {
+// ScheduledEvent captures the usage of all existing MCPs up to now. Its duration, failures and the number of MCPUsages
+// without a charging target are recorded in the metrics.
+func (u *UsageTracker) ScheduledEvent(ctx context.Context) (err error) {
+	start := time.Now()
+	defer func() {
+		metrics.ScheduledEventDuration.Observe(time.Since(start).Seconds())
+		if err != nil {
+			metrics.ScheduledEventFailures.Inc()
+			return
+		}
+		metrics.RecordSuccessfulCapture(time.Now())
+	}()
+
+	return u.scheduledEvent(ctx)
+}
+
+func (u *UsageTracker) scheduledEvent(ctx context.Context) error {
 	log := logf.FromContext(ctx).WithName("scheduled")
 
-	var mcpUsages v1.MCPUsageList
+	var mcpUsages v2.MCPUsageList
 	err := u.client.List(ctx, &mcpUsages)
 	if err != nil {
 		return fmt.Errorf("error when getting list of mcp usages: %w", err)
 	}
 
-	now := time.Now().UTC()
+	now := u.now()
 
+	missingChargingTargets := 0
 	var errs error
 	for _, mcpUsage := range mcpUsages.Items {
+		if mcpUsage.Status.UsageOperator.MCPDeletedAt.IsZero() {
+			// the usage until now still belongs to the expired charging target, the rest is captured below
+			expired, err := u.chargingTargetExpired(ctx, &mcpUsage, now)
+			if err != nil {
+				errs = errors.Join(errs, err)
+			} else if expired {
+				log.Info("charging target expired, resolving it again", "mcpUsage", mcpUsage.Name, "chargingTarget", mcpUsage.Status.UsageOperator.ChargingTarget)
+				err = u.UpdateChargingTarget(ctx, mcpUsage.Spec.Project, mcpUsage.Spec.Workspace, mcpUsage.Spec.MCP, mcpUsage.Status.UsageOperator.MCPUID)
+				if err != nil {
+					errs = errors.Join(errs, fmt.Errorf("error when resolving the expired charging target of MCPUsage %s: %w", mcpUsage.Name, err))
+				}
+			}
+		}
+
 		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
 			var project, workspace, mcp_name = mcpUsage.Spec.Project, mcpUsage.Spec.Workspace, mcpUsage.Spec.MCP
 			log = log.WithValues(
@@ -222,33 +777,37 @@ func (u *UsageTracker) ScheduledEvent(ctx context.Context) error {
 				return err
 			}
 
-			if !mcpUsage.Spec.MCPDeletedAt.IsZero() {
+			if !mcpUsage.Status.UsageOperator.MCPDeletedAt.IsZero() {
 				// mcp does not exist anymore
 				return nil
 			}
 
-			usages := calculateUsage(now, mcpUsage.Spec.LastUsageCaptured.Time)
-			usages = MergeDailyUsages(usages, mcpUsage.Spec.Usage)
-
-			mcpUsage.Spec.Usage = usages
-			mcpUsage.Spec.LastUsageCaptured = metav1.NewTime(now)
-			err = u.client.Update(ctx, &mcpUsage)
+			captured := u.captureUsage(log, &mcpUsage, now)
+			err = u.updateStatus(ctx, &mcpUsage, condition(v2.ConditionUsageCurrent, metav1.ConditionTrue, reasonCaptured, ""))
 			if err != nil {
 				if k8serrors.IsConflict(err) {
 					log.Error(err, "Conflict detected for McpUsage, retrying...\n", "mcpUsage", mcpUsage.Name)
 					return err
 				}
+				u.reportCaptureFailure(ctx, log, mcpUsage.Name, err)
 				return fmt.Errorf("failed to update McpUsage %s: %w", mcpUsage.Name, err)
 			}
+			metrics.RecordUsage(project, workspace, captured)
 
 			return nil
 		})
 
+		if mcpUsage.Status.UsageOperator.MCPDeletedAt.IsZero() && isChargingTargetMissing(&mcpUsage) {
+			missingChargingTargets++
+		}
+
 		if err != nil {
 			errs = errors.Join(errs, err)
 		}
 	}
 
+	metrics.MissingChargingTarget.Set(float64(missingChargingTargets))
+
 	if errs != nil {
 		return fmt.Errorf("error when updating the usage: %w", errs)
 	}
@@ -259,21 +818,28 @@ func (u *UsageTracker) ScheduledEvent(ctx context.Context) error {
 func (u *UsageTracker) GarbageCollection(ctx context.Context) error {
 	log := logf.FromContext(ctx).WithName("garbage")
 
-	var mcpUsages v1.MCPUsageList
+	var mcpUsages v2.MCPUsageList
 	err := u.client.List(ctx, &mcpUsages)
 	if err != nil {
 		return fmt.Errorf("error when getting list of mcp usages: %w", err)
 	}
 
+	openMonths, err := u.openMonths(ctx)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now().UTC().Truncate(time.Hour * 24)
-	oneMonth := time.Hour * 24 * 32
-	latestTimestamp := now.Add(-oneMonth)
 
-	log.Info("garbage collect old entries", "before", latestTimestamp)
+	log.Info("garbage collect old entries", "retention", u.retention, "dryRun", u.gcDryRun, "requireAcknowledgement", u.requireAck, "maxAge", u.maxAge)
 
 	var errs error
+	var retainedTotal int
 	for _, mcpUsage := range mcpUsages.Items {
+		// retained is the number of unacknowledged entries of the MCPUsage, which are kept beyond the retention
+		var retained int
 		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			retained = 0
 			err = u.client.Get(ctx, client.ObjectKey{
 				Name: mcpUsage.Name,
 			}, &mcpUsage)
@@ -281,14 +847,49 @@ func (u *UsageTracker) GarbageCollection(ctx context.Context) error {
 				return err
 			}
 
-			usagesToKeep := make([]v1.DailyUsage, 0, len(mcpUsage.Spec.Usage))
-			for _, usage := range mcpUsage.Spec.Usage {
+			// the months are rolled up before the daily usage is pruned, so the pruned days are kept in the rollup
+			err = u.rollupMonths(ctx, log, &mcpUsage, openMonths[mcpUsage.Name], time.Now().UTC())
+			if err != nil {
+				if k8serrors.IsConflict(err) {
+					return err
+				}
+				return fmt.Errorf("failed to roll up the months of McpUsage %s, nothing is pruned: %w", mcpUsage.Name, err)
+			}
+
+			retention, err := getRetention(&mcpUsage, u.retention)
+			if err != nil {
+				log.Error(err, "invalid retention annotation, falling back to the default retention", "mcpUsage", mcpUsage.Name, "retention", u.retention)
+			}
+			latestTimestamp := now.Add(-retention)
+
+			usagesToKeep := make([]v2.DailyUsage, 0, len(mcpUsage.Status.UsageOperator.Usage))
+			var unacknowledged []string
+			for _, usage := range mcpUsage.Status.UsageOperator.Usage {
 				if !usage.Date.Time.Before(latestTimestamp) {
 					usagesToKeep = append(usagesToKeep, usage)
+					continue
 				}
+				if u.requireAck && !isAcknowledged(&mcpUsage, usage) {
+					if u.maxAge == 0 || !usage.Date.Time.Before(now.Add(-u.maxAge)) {
+						log.Info("keeping unacknowledged usage entry", "mcpUsage", mcpUsage.Name, "date", usage.Date, "before", latestTimestamp)
+						usagesToKeep = append(usagesToKeep, usage)
+						retained++
+						continue
+					}
+					log.Info("unacknowledged usage entry exceeded the max age", "mcpUsage", mcpUsage.Name, "date", usage.Date, "maxAge", u.maxAge)
+					unacknowledged = append(unacknowledged, usage.Date.UTC().Format(time.RFC3339))
+				}
+				if u.gcDryRun {
+					log.Info("would prune usage entry", "mcpUsage", mcpUsage.Name, "date", usage.Date, "usage", usage.Usage, "before", latestTimestamp)
+				}
+			}
+			if u.gcDryRun || len(usagesToKeep) == len(mcpUsage.Status.UsageOperator.Usage) {
+				return nil
 			}
-			mcpUsage.Spec.Usage = usagesToKeep
-			err = u.client.Update(ctx, &mcpUsage)
+
+			pruned := len(mcpUsage.Status.UsageOperator.Usage) - len(usagesToKeep)
+			mcpUsage.Status.UsageOperator.Usage = usagesToKeep
+			err = u.updateStatus(ctx, &mcpUsage)
 			if err != nil {
 				if k8serrors.IsConflict(err) {
 					log.Error(err, "Conflict detected for McpUsage, retrying...\n", "mcpUsage", mcpUsage.Name)
@@ -296,14 +897,21 @@ func (u *UsageTracker) GarbageCollection(ctx context.Context) error {
 				}
 				return fmt.Errorf("failed to update McpUsage %s: %w", mcpUsage.Name, err)
 			}
+			metrics.GarbageCollectedEntries.Add(float64(pruned))
+			u.event(&mcpUsage, corev1.EventTypeNormal, "UsagePruned", "GarbageCollect", "pruned %d daily usage entries before %s", pruned, latestTimestamp.Format(time.DateOnly))
+			if len(unacknowledged) > 0 {
+				u.event(&mcpUsage, corev1.EventTypeWarning, "UnacknowledgedUsagePruned", "GarbageCollect", "pruned daily usage entries after the max age of %s without acknowledgement: %s", u.maxAge, strings.Join(unacknowledged, ", "))
+			}
 
 			return nil
 		})
 
+		retainedTotal += retained
 		if err != nil {
 			errs = errors.Join(errs, fmt.Errorf("error when updating the mcp usage resource: %w", err))
 		}
 	}
+	metrics.UnreportedDaysRetained.Set(float64(retainedTotal))
 
 	if errs != nil {
 		return fmt.Errorf("error when updating the usage: %w", errs)
@@ -2,15 +2,30 @@ package usage
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	v1 "github.com/openmcp-project/usage-operator/api/usage/v1"
+	v2 "github.com/openmcp-project/usage-operator/api/usage/v2"
+	"github.com/openmcp-project/usage-operator/internal/cloudevents"
+	"github.com/openmcp-project/usage-operator/internal/config"
+	"github.com/openmcp-project/usage-operator/internal/helper"
+	"github.com/openmcp-project/usage-operator/internal/metrics"
 )
 
 const (
@@ -25,20 +40,23 @@ var _ = Describe("Tracking Module", Ordered, func() {
 	BeforeAll(func() {
 		ctx := context.Background()
 		creationTime := metav1.NewTime(metav1.Now().Add(-time.Hour * 4))
-		mcpUsage := v1.MCPUsage{
+		mcpUsage := v2.MCPUsage{
 			ObjectMeta: metav1.ObjectMeta{
 				Name: mcpUsageName,
 			},
-			Spec: v1.MCPUsageSpec{
-				ChargingTarget:    "missing",
-				Project:           projectName,
-				Workspace:         workspaceName,
-				MCP:               mcpName,
-				MCPCreatedAt:      creationTime,
-				LastUsageCaptured: creationTime,
+			Spec: v2.MCPUsageSpec{
+				Project:   projectName,
+				Workspace: workspaceName,
+				MCP:       mcpName,
 			},
 		}
 		Expect(k8sClient.Create(ctx, &mcpUsage)).Should(Succeed())
+		mcpUsage.Status.UsageOperator = v2.UsageOperatorStatus{
+			ChargingTarget:    "missing",
+			MCPCreatedAt:      creationTime,
+			LastUsageCaptured: creationTime,
+		}
+		Expect(k8sClient.Status().Update(ctx, &mcpUsage)).Should(Succeed())
 	})
 
 	It("Check scheduled Event", func() {
@@ -49,20 +67,20 @@ var _ = Describe("Tracking Module", Ordered, func() {
 
 		Expect(usageTracker.ScheduledEvent(ctx)).Should(Succeed())
 
-		mcpUsage := v1.MCPUsage{
+		mcpUsage := v2.MCPUsage{
 			ObjectMeta: metav1.ObjectMeta{
 				Name: mcpUsageName,
 			},
 		}
 		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&mcpUsage), &mcpUsage)).Should(Succeed())
 
-		Expect(mcpUsage.Spec.Usage).ShouldNot(BeEmpty())
+		Expect(mcpUsage.Status.UsageOperator.Usage).ShouldNot(BeEmpty())
 	})
 
 	It("garbage collect old usage data", func() {
 		ctx := context.Background()
 
-		mcpUsage := v1.MCPUsage{
+		mcpUsage := v2.MCPUsage{
 			ObjectMeta: metav1.ObjectMeta{
 				Name: mcpUsageName,
 			},
@@ -70,7 +88,7 @@ var _ = Describe("Tracking Module", Ordered, func() {
 		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&mcpUsage), &mcpUsage)).Should(Succeed())
 
 		now := metav1.Now()
-		mcpUsage.Spec.Usage = []v1.DailyUsage{
+		mcpUsage.Status.UsageOperator.Usage = []v2.DailyUsage{
 			{
 				Date: metav1.NewTime(now.Add(-time.Hour * 4)),
 				Usage: metav1.Duration{
@@ -84,7 +102,176 @@ var _ = Describe("Tracking Module", Ordered, func() {
 				},
 			},
 		}
+		Expect(k8sClient.Status().Update(ctx, &mcpUsage)).Should(Succeed())
+
+		usageTracker, err := NewUsageTracker(k8sClient)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(usageTracker.GarbageCollection(ctx)).Should(Succeed())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&mcpUsage), &mcpUsage)).Should(Succeed())
+		Expect(mcpUsage.Status.UsageOperator.Usage).Should(HaveLen(1))
+	})
+
+	It("should not prune entries in dry run mode", func() {
+		ctx := context.Background()
+
+		mcpUsage := v2.MCPUsage{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: mcpUsageName,
+			},
+		}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&mcpUsage), &mcpUsage)).Should(Succeed())
+
+		now := metav1.Now()
+		mcpUsage.Status.UsageOperator.Usage = []v2.DailyUsage{
+			{
+				Date: metav1.NewTime(now.Add(-time.Hour * 24 * 40)),
+				Usage: metav1.Duration{
+					Duration: time.Hour * 4,
+				},
+			},
+		}
+		Expect(k8sClient.Status().Update(ctx, &mcpUsage)).Should(Succeed())
+
+		usageTracker, err := NewUsageTracker(k8sClient)
+		Expect(err).ShouldNot(HaveOccurred())
+		usageTracker.WithGarbageCollectionDryRun(true)
+
+		Expect(usageTracker.GarbageCollection(ctx)).Should(Succeed())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&mcpUsage), &mcpUsage)).Should(Succeed())
+		Expect(mcpUsage.Status.UsageOperator.Usage).Should(HaveLen(1))
+	})
+
+	It("should keep unacknowledged entries, if an acknowledgement is required", func() {
+		ctx := context.Background()
+
+		mcpUsage := v2.MCPUsage{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: mcpUsageName,
+			},
+		}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&mcpUsage), &mcpUsage)).Should(Succeed())
+
+		reported := metav1.NewTime(time.Now().Add(-time.Hour * 24 * 40).Truncate(time.Second))
+		unreported := metav1.NewTime(time.Now().Add(-time.Hour * 24 * 41).Truncate(time.Second))
+		mcpUsage.Status.UsageOperator.Usage = []v2.DailyUsage{
+			{Date: unreported, Usage: metav1.Duration{Duration: time.Hour * 4}},
+			{Date: reported, Usage: metav1.Duration{Duration: time.Hour * 4}},
+		}
+		mcpUsage.Status.DailyUsageReport = []v2.DailyUsageReport{
+			{Date: unreported, Status: v2.ReportStatusFailed, Message: "charging target missing"},
+			{Date: reported, Status: v2.ReportStatusReported, ContentHash: contentHash(mcpUsage.Status.UsageOperator.Usage[1])},
+		}
+		Expect(k8sClient.Status().Update(ctx, &mcpUsage)).Should(Succeed())
+
+		usageTracker, err := NewUsageTracker(k8sClient)
+		Expect(err).ShouldNot(HaveOccurred())
+		usageTracker.WithRequireAcknowledgement(true)
+
+		Expect(usageTracker.GarbageCollection(ctx)).Should(Succeed())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&mcpUsage), &mcpUsage)).Should(Succeed())
+		Expect(mcpUsage.Status.UsageOperator.Usage).Should(HaveLen(1))
+		Expect(mcpUsage.Status.UsageOperator.Usage[0].Date.Equal(&unreported)).Should(BeTrue())
+		Expect(mcpUsage.Status.UsageOperator.Usage[0].ContentHash).Should(Equal(contentHash(mcpUsage.Status.UsageOperator.Usage[0])))
+	})
+
+	It("should prune unacknowledged entries after the max age", func() {
+		ctx := context.Background()
+
+		mcpUsage := v2.MCPUsage{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: mcpUsageName,
+			},
+		}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&mcpUsage), &mcpUsage)).Should(Succeed())
+
+		now := metav1.Now()
+		mcpUsage.Status.UsageOperator.Usage = []v2.DailyUsage{
+			{Date: metav1.NewTime(now.Add(-time.Hour * 24 * 100)), Usage: metav1.Duration{Duration: time.Hour * 4}},
+			{Date: metav1.NewTime(now.Add(-time.Hour * 24 * 40)), Usage: metav1.Duration{Duration: time.Hour * 4}},
+		}
+		mcpUsage.Status.DailyUsageReport = nil
+		Expect(k8sClient.Status().Update(ctx, &mcpUsage)).Should(Succeed())
+
+		usageTracker, err := NewUsageTracker(k8sClient)
+		Expect(err).ShouldNot(HaveOccurred())
+		usageTracker.WithRequireAcknowledgement(true).WithMaxAge(time.Hour * 24 * 60)
+
+		Expect(usageTracker.GarbageCollection(ctx)).Should(Succeed())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&mcpUsage), &mcpUsage)).Should(Succeed())
+		Expect(mcpUsage.Status.UsageOperator.Usage).Should(HaveLen(1))
+		Expect(mcpUsage.Status.UsageOperator.Usage[0].Date.Time).Should(BeTemporally("~", now.Add(-time.Hour*24*40), time.Second))
+		Expect(testutil.ToFloat64(metrics.UnreportedDaysRetained)).Should(BeNumerically(">=", 1))
+	})
+
+	It("should supersede the report of a day, which changed after it was reported", func() {
+		ctx := context.Background()
+
+		mcpUsage := v2.MCPUsage{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: mcpUsageName,
+			},
+		}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&mcpUsage), &mcpUsage)).Should(Succeed())
+
+		date := metav1.NewTime(time.Now().Add(-time.Hour * 24 * 2).Truncate(time.Second))
+		day := v2.DailyUsage{Date: date, Usage: metav1.Duration{Duration: time.Hour * 4}}
+		mcpUsage.Status.UsageOperator.Usage = []v2.DailyUsage{day}
+		mcpUsage.Status.DailyUsageReport = []v2.DailyUsageReport{
+			{Date: date, Status: v2.ReportStatusReported, ContentHash: contentHash(day)},
+		}
+		Expect(k8sClient.Status().Update(ctx, &mcpUsage)).Should(Succeed())
+
+		// the day changes, e.g. because a late capture added usage to it
+		mcpUsage.Status.UsageOperator.Usage[0].Usage.Duration += time.Hour
+		recorder := events.NewFakeRecorder(5)
+		usageTracker, err := NewUsageTracker(k8sClient)
+		Expect(err).ShouldNot(HaveOccurred())
+		usageTracker.WithEventRecorder(recorder)
+		Expect(usageTracker.updateStatus(ctx, &mcpUsage)).Should(Succeed())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&mcpUsage), &mcpUsage)).Should(Succeed())
+		Expect(mcpUsage.Status.DailyUsageReport[0].Status).Should(Equal(v2.ReportStatusSuperseded))
+		Expect(recorder.Events).Should(Receive(ContainSubstring("ReportSuperseded")))
+	})
+
+	It("should respect the retention annotation of an mcp usage resource", func() {
+		ctx := context.Background()
+
+		mcpUsage := v2.MCPUsage{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: mcpUsageName,
+			},
+		}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&mcpUsage), &mcpUsage)).Should(Succeed())
+
+		now := metav1.Now()
+		mcpUsage.SetAnnotations(map[string]string{
+			v2.RetentionAnnotation: "2208h",
+		})
+		mcpUsage.Status.UsageOperator.MCPDeletedAt = metav1.NewTime(now.Add(-time.Hour * 24 * 30))
+		mcpUsage.Status.UsageOperator.Usage = []v2.DailyUsage{
+			{
+				Date: metav1.NewTime(now.Add(-time.Hour * 24 * 40)),
+				Usage: metav1.Duration{
+					Duration: time.Hour * 4,
+				},
+			},
+			{
+				Date: metav1.NewTime(now.Add(-time.Hour * 24 * 100)),
+				Usage: metav1.Duration{
+					Duration: time.Hour * 4,
+				},
+			},
+		}
+		status := mcpUsage.Status.DeepCopy()
 		Expect(k8sClient.Update(ctx, &mcpUsage)).Should(Succeed())
+		mcpUsage.Status = *status
+		Expect(k8sClient.Status().Update(ctx, &mcpUsage)).Should(Succeed())
 
 		usageTracker, err := NewUsageTracker(k8sClient)
 		Expect(err).ShouldNot(HaveOccurred())
@@ -92,7 +279,8 @@ var _ = Describe("Tracking Module", Ordered, func() {
 		Expect(usageTracker.GarbageCollection(ctx)).Should(Succeed())
 
 		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&mcpUsage), &mcpUsage)).Should(Succeed())
-		Expect(mcpUsage.Spec.Usage).Should(HaveLen(1))
+		Expect(mcpUsage.Status.UsageOperator.Usage).Should(HaveLen(1))
+		Expect(mcpUsage.Status.UsageOperator.Usage[0].Date.Time).Should(BeTemporally("~", now.Add(-time.Hour*24*40), time.Second))
 	})
 
 	It("should create an mcp usage resource", func() {
@@ -104,9 +292,9 @@ var _ = Describe("Tracking Module", Ordered, func() {
 		objectKey, err := GetObjectKey(projectName, workspaceName, mcpName)
 		Expect(err).ShouldNot(HaveOccurred())
 
-		Expect(usageTracker.CreateOrUpdateEvent(ctx, projectName, workspaceName, mcpName)).Should(Succeed())
+		Expect(usageTracker.CreateOrUpdateEvent(ctx, projectName, workspaceName, mcpName, "", "Ready")).Should(Succeed())
 
-		var mcpUsage v1.MCPUsage
+		var mcpUsage v2.MCPUsage
 		Expect(k8sClient.Get(ctx, objectKey, &mcpUsage)).Should(Succeed())
 
 		Expect(mcpUsage.Spec.Project).Should(Equal(projectName))
@@ -123,15 +311,366 @@ var _ = Describe("Tracking Module", Ordered, func() {
 		objectKey, err := GetObjectKey(projectName, workspaceName, mcpName)
 		Expect(err).ShouldNot(HaveOccurred())
 
-		Expect(usageTracker.CreateOrUpdateEvent(ctx, projectName, workspaceName, mcpName)).Should(Succeed())
-		Expect(usageTracker.DeletionEvent(ctx, projectName, workspaceName, mcpName)).Should(Succeed())
+		Expect(usageTracker.CreateOrUpdateEvent(ctx, projectName, workspaceName, mcpName, "", "Ready")).Should(Succeed())
+		Expect(usageTracker.DeletionEvent(ctx, projectName, workspaceName, mcpName, "")).Should(Succeed())
 
-		var mcpUsage v1.MCPUsage
+		var mcpUsage v2.MCPUsage
 		Expect(k8sClient.Get(ctx, objectKey, &mcpUsage)).Should(Succeed())
 
-		Expect(mcpUsage.Spec.MCPDeletedAt.IsZero()).Should(BeFalse())
+		Expect(mcpUsage.Status.UsageOperator.MCPDeletedAt.IsZero()).Should(BeFalse())
 
 		// It should also handle events for already deleted mcps
-		Expect(usageTracker.CreateOrUpdateEvent(ctx, projectName, workspaceName, mcpName)).Should(Succeed())
+		Expect(usageTracker.CreateOrUpdateEvent(ctx, projectName, workspaceName, mcpName, "", "Ready")).Should(Succeed())
+	})
+	It("should capture the time in non-billable phases separately", func() {
+		ctx := context.Background()
+		phaseMCPName := "mcp-phase-test"
+
+		usageTracker, err := NewUsageTracker(k8sClient)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		objectKey, err := GetObjectKey(projectName, workspaceName, phaseMCPName)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(usageTracker.CreateOrUpdateEvent(ctx, projectName, workspaceName, phaseMCPName, "", "")).Should(Succeed())
+
+		var mcpUsage v2.MCPUsage
+		Expect(k8sClient.Get(ctx, objectKey, &mcpUsage)).Should(Succeed())
+		Expect(mcpUsage.Status.UsageOperator.MCPPhase).Should(Equal(v2.MCPPhasePending))
+
+		// the mcp was provisioning for two hours
+		mcpUsage.Status.UsageOperator.LastUsageCaptured = metav1.NewTime(mcpUsage.Status.UsageOperator.LastUsageCaptured.Add(-2 * time.Hour))
+		Expect(k8sClient.Status().Update(ctx, &mcpUsage)).Should(Succeed())
+
+		Expect(usageTracker.UpdatePhase(ctx, projectName, workspaceName, phaseMCPName, "", "Ready")).Should(Succeed())
+
+		Expect(k8sClient.Get(ctx, objectKey, &mcpUsage)).Should(Succeed())
+		Expect(mcpUsage.Status.UsageOperator.MCPPhase).Should(Equal("Ready"))
+
+		var billable, nonBillable time.Duration
+		for _, usage := range mcpUsage.Status.UsageOperator.Usage {
+			billable += usage.Usage.Duration
+			nonBillable += usage.NonBillableUsage.Duration
+		}
+		Expect(billable).Should(BeZero())
+		Expect(nonBillable).Should(BeNumerically(">=", 2*time.Hour))
+	})
+	It("should mark an orphaned mcp usage resource as deleted at the given time", func() {
+		ctx := context.Background()
+		orphanMCPName := "mcp-orphan-test"
+
+		usageTracker, err := NewUsageTracker(k8sClient)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		objectKey, err := GetObjectKey(projectName, workspaceName, orphanMCPName)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(usageTracker.CreateOrUpdateEvent(ctx, projectName, workspaceName, orphanMCPName, "", "Ready")).Should(Succeed())
+
+		var mcpUsage v2.MCPUsage
+		Expect(k8sClient.Get(ctx, objectKey, &mcpUsage)).Should(Succeed())
+		lastUsageCaptured := mcpUsage.Status.UsageOperator.LastUsageCaptured.Time
+
+		Expect(usageTracker.OrphanEvent(ctx, projectName, workspaceName, orphanMCPName, "", lastUsageCaptured, "orphaned")).Should(Succeed())
+
+		Expect(k8sClient.Get(ctx, objectKey, &mcpUsage)).Should(Succeed())
+		Expect(mcpUsage.Status.UsageOperator.MCPDeletedAt.Time).Should(BeTemporally("==", lastUsageCaptured))
+		Expect(mcpUsage.Status.UsageOperator.Message).Should(Equal("orphaned"))
+	})
+	It("should bill a re-created mcp again", func() {
+		ctx := context.Background()
+		recreatedMCPName := "mcp-recreation-test"
+
+		usageTracker, err := NewUsageTracker(k8sClient)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		objectKey, err := GetObjectKey(projectName, workspaceName, recreatedMCPName)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(usageTracker.CreateOrUpdateEvent(ctx, projectName, workspaceName, recreatedMCPName, "uid-1", "Ready")).Should(Succeed())
+		Expect(usageTracker.DeletionEvent(ctx, projectName, workspaceName, recreatedMCPName, "uid-1")).Should(Succeed())
+		Expect(usageTracker.CreateOrUpdateEvent(ctx, projectName, workspaceName, recreatedMCPName, "uid-2", "Ready")).Should(Succeed())
+
+		var mcpUsage v2.MCPUsage
+		Expect(k8sClient.Get(ctx, objectKey, &mcpUsage)).Should(Succeed())
+		Expect(mcpUsage.Status.UsageOperator.MCPDeletedAt.IsZero()).Should(BeTrue())
+		Expect(mcpUsage.Status.UsageOperator.Lifecycle).Should(HaveLen(2))
+		Expect(mcpUsage.Status.UsageOperator.Lifecycle[0].UID).Should(BeEquivalentTo("uid-1"))
+		Expect(mcpUsage.Status.UsageOperator.Lifecycle[0].DeletedAt.IsZero()).Should(BeFalse())
+		Expect(mcpUsage.Status.UsageOperator.Lifecycle[1].UID).Should(BeEquivalentTo("uid-2"))
+
+		// the re-created mcp is captured by the scheduled event again
+		mcpUsage.Status.UsageOperator.LastUsageCaptured = metav1.NewTime(mcpUsage.Status.UsageOperator.LastUsageCaptured.Add(-time.Hour))
+		Expect(k8sClient.Status().Update(ctx, &mcpUsage)).Should(Succeed())
+		Expect(usageTracker.ScheduledEvent(ctx)).Should(Succeed())
+
+		Expect(k8sClient.Get(ctx, objectKey, &mcpUsage)).Should(Succeed())
+		var billable time.Duration
+		for _, usage := range mcpUsage.Status.UsageOperator.Usage {
+			billable += usage.Usage.Duration
+		}
+		Expect(billable).Should(BeNumerically(">=", time.Hour))
+	})
+	It("should not merge a new incarnation into the previous one", func() {
+		ctx := context.Background()
+		uidMCPName := "mcp-uid-test"
+
+		usageTracker, err := NewUsageTracker(k8sClient)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		objectKey, err := GetObjectKey(projectName, workspaceName, uidMCPName)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(usageTracker.CreateOrUpdateEvent(ctx, projectName, workspaceName, uidMCPName, "uid-a", "Ready")).Should(Succeed())
+		// the deletion of uid-a is never seen
+		Expect(usageTracker.CreateOrUpdateEvent(ctx, projectName, workspaceName, uidMCPName, "uid-b", "Ready")).Should(Succeed())
+
+		var mcpUsage v2.MCPUsage
+		Expect(k8sClient.Get(ctx, objectKey, &mcpUsage)).Should(Succeed())
+		Expect(mcpUsage.Status.UsageOperator.MCPUID).Should(BeEquivalentTo("uid-b"))
+		Expect(mcpUsage.Labels).Should(HaveKeyWithValue(v2.MCPUIDLabel, "uid-b"))
+		Expect(mcpUsage.Status.UsageOperator.Lifecycle).Should(HaveLen(2))
+		Expect(mcpUsage.Status.UsageOperator.Lifecycle[0].UID).Should(BeEquivalentTo("uid-a"))
+		Expect(mcpUsage.Status.UsageOperator.Lifecycle[0].DeletedAt.IsZero()).Should(BeFalse())
+	})
+
+	It("should key new mcp usage resources by uid and migrate existing ones", func() {
+		ctx := context.Background()
+		legacyMCPName := "mcp-legacy-test"
+
+		legacyTracker, err := NewUsageTracker(k8sClient)
+		Expect(err).ShouldNot(HaveOccurred())
+		usageTracker, err := NewUsageTracker(k8sClient)
+		Expect(err).ShouldNot(HaveOccurred())
+		usageTracker.WithKeyByUID(true)
+
+		legacyKey, err := GetObjectKey(projectName, workspaceName, legacyMCPName)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		// created before the uid was tracked
+		Expect(legacyTracker.CreateOrUpdateEvent(ctx, projectName, workspaceName, legacyMCPName, "", "Ready")).Should(Succeed())
+
+		// the existing record is adopted by the mcp
+		Expect(usageTracker.CreateOrUpdateEvent(ctx, projectName, workspaceName, legacyMCPName, "uid-legacy", "Ready")).Should(Succeed())
+		var mcpUsage v2.MCPUsage
+		Expect(k8sClient.Get(ctx, legacyKey, &mcpUsage)).Should(Succeed())
+		Expect(mcpUsage.Status.UsageOperator.MCPUID).Should(BeEquivalentTo("uid-legacy"))
+
+		// a new incarnation gets its own record, the previous one is ended
+		Expect(usageTracker.CreateOrUpdateEvent(ctx, projectName, workspaceName, legacyMCPName, "uid-new", "Ready")).Should(Succeed())
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "uid-new"}, &mcpUsage)).Should(Succeed())
+		Expect(mcpUsage.Spec.MCP).Should(Equal(legacyMCPName))
+		Expect(mcpUsage.Status.UsageOperator.MCPDeletedAt.IsZero()).Should(BeTrue())
+
+		Expect(k8sClient.Get(ctx, legacyKey, &mcpUsage)).Should(Succeed())
+		Expect(mcpUsage.Status.UsageOperator.MCPDeletedAt.IsZero()).Should(BeFalse())
+		Expect(mcpUsage.Status.UsageOperator.MCPUID).Should(BeEquivalentTo("uid-legacy"))
+	})
+	It("should write conditions without touching the daily usage report", func() {
+		ctx := context.Background()
+		conditionMCPName := "mcp-condition-test"
+
+		usageTracker, err := NewUsageTracker(k8sClient)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		objectKey, err := GetObjectKey(projectName, workspaceName, conditionMCPName)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(usageTracker.CreateOrUpdateEvent(ctx, projectName, workspaceName, conditionMCPName, "", "Ready")).Should(Succeed())
+
+		var mcpUsage v2.MCPUsage
+		Expect(k8sClient.Get(ctx, objectKey, &mcpUsage)).Should(Succeed())
+		Expect(meta.IsStatusConditionTrue(mcpUsage.Status.UsageOperator.Conditions, v2.ConditionMCPPresent)).Should(BeTrue())
+		Expect(meta.FindStatusCondition(mcpUsage.Status.UsageOperator.Conditions, v2.ConditionChargingTargetResolved)).ShouldNot(BeNil())
+
+		// the metering operator reports a day
+		report := v2.DailyUsageReport{Date: metav1.NewTime(time.Date(2025, 7, 22, 0, 0, 0, 0, time.UTC)), Status: v2.ReportStatusReported}
+		mcpUsage.Status.DailyUsageReport = []v2.DailyUsageReport{report}
+		Expect(k8sClient.Status().Update(ctx, &mcpUsage)).Should(Succeed())
+
+		Expect(usageTracker.DeletionEvent(ctx, projectName, workspaceName, conditionMCPName, "")).Should(Succeed())
+
+		Expect(k8sClient.Get(ctx, objectKey, &mcpUsage)).Should(Succeed())
+		Expect(mcpUsage.Status.DailyUsageReport).Should(HaveLen(1))
+		Expect(mcpUsage.Status.DailyUsageReport[0].Status).Should(Equal(v2.ReportStatusReported))
+		Expect(meta.IsStatusConditionFalse(mcpUsage.Status.UsageOperator.Conditions, v2.ConditionMCPPresent)).Should(BeTrue())
+		Expect(meta.IsStatusConditionTrue(mcpUsage.Status.UsageOperator.Conditions, v2.ConditionUsageCurrent)).Should(BeTrue())
+		Expect(mcpUsage.Status.UsageOperator.ObservedGeneration).Should(Equal(mcpUsage.Generation))
+	})
+
+	It("should notice a charging target, which expired by time alone", func() {
+		ctx := context.Background()
+
+		cfg := config.New().ChargingTarget
+		cfg.Validate = true
+		resolver, err := helper.NewChargingTargetResolver(cfg)
+		Expect(err).ShouldNot(HaveOccurred())
+		usageTracker, err := NewUsageTracker(k8sClient)
+		Expect(err).ShouldNot(HaveOccurred())
+		usageTracker.WithChargingTargetResolver(resolver)
+
+		validUntil := metav1.NewTime(time.Now().Add(time.Hour))
+		Expect(k8sClient.Create(ctx, &v2.ChargingTarget{
+			ObjectMeta: metav1.ObjectMeta{Name: "cc-expiring"},
+			Spec:       v2.ChargingTargetSpec{Type: "cost-center", ValidUntil: &validUntil},
+		})).Should(Succeed())
+		mcpUsage := &v2.MCPUsage{Status: v2.MCPUsageStatus{UsageOperator: v2.UsageOperatorStatus{
+			ChargingTarget:     "cc-expiring",
+			ChargingTargetType: "cost-center",
+			Conditions:         []metav1.Condition{condition(v2.ConditionChargingTargetValid, metav1.ConditionTrue, reasonRegistered, "")},
+		}}}
+
+		Expect(usageTracker.chargingTargetExpired(ctx, mcpUsage, time.Now())).Should(BeFalse())
+		Expect(usageTracker.chargingTargetExpired(ctx, mcpUsage, validUntil.Time)).Should(BeTrue())
+	})
+
+	It("should only store a new mcp usage resource together with its CloudEvent", func() {
+		ctx := context.Background()
+		publishedMCPName := "mcp-publish-test"
+
+		dir := GinkgoT().TempDir()
+		publisher, err := cloudevents.NewPublisher("http://localhost", "/usage-operator", dir)
+		Expect(err).ShouldNot(HaveOccurred())
+		usageTracker, err := NewUsageTracker(k8sClient)
+		Expect(err).ShouldNot(HaveOccurred())
+		usageTracker.WithPublisher(publisher)
+		objectKey, err := GetObjectKey(projectName, workspaceName, publishedMCPName)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		// the outbox can't be written
+		Expect(os.RemoveAll(dir)).To(Succeed())
+		Expect(usageTracker.CreateOrUpdateEvent(ctx, projectName, workspaceName, publishedMCPName, "", "Ready")).ShouldNot(Succeed())
+		Expect(k8serrors.IsNotFound(k8sClient.Get(ctx, objectKey, &v2.MCPUsage{}))).Should(BeTrue())
+
+		Expect(os.MkdirAll(dir, 0o755)).To(Succeed())
+		Expect(usageTracker.CreateOrUpdateEvent(ctx, projectName, workspaceName, publishedMCPName, "", "Ready")).Should(Succeed())
+		Expect(k8sClient.Get(ctx, objectKey, &v2.MCPUsage{})).Should(Succeed())
+		// the created event and the charging target, which is missing as the project doesn't exist
+		files, err := os.ReadDir(dir)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(files).Should(HaveLen(2))
+	})
+
+	It("should publish the same mcp.created event again, if storing a new interval is retried", func() {
+		ctx := context.Background()
+		conflictMCPName := "mcp-conflict-test"
+
+		objectKey, err := GetObjectKey(projectName, workspaceName, conflictMCPName)
+		Expect(err).ShouldNot(HaveOccurred())
+		deletedAt := metav1.NewTime(time.Now().UTC().Add(-time.Hour).Truncate(time.Second))
+		deleted := &v2.MCPUsage{
+			ObjectMeta: metav1.ObjectMeta{Name: objectKey.Name},
+			Spec:       v2.MCPUsageSpec{Project: projectName, Workspace: workspaceName, MCP: conflictMCPName},
+			Status: v2.MCPUsageStatus{UsageOperator: v2.UsageOperatorStatus{
+				ChargingTarget:    "missing",
+				MCPCreatedAt:      metav1.NewTime(deletedAt.Add(-time.Hour)),
+				MCPDeletedAt:      deletedAt,
+				LastUsageCaptured: deletedAt,
+			}},
+		}
+
+		// the first update of the status, which starts the new interval, conflicts
+		conflicts := 1
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithStatusSubresource(&v2.MCPUsage{}).WithObjects(deleted).
+			WithInterceptorFuncs(interceptor.Funcs{
+				SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+					if conflicts > 0 {
+						conflicts--
+						return k8serrors.NewConflict(v2.GroupVersion.WithResource("mcpusages").GroupResource(), obj.GetName(), errors.New("conflict"))
+					}
+					return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+				},
+			}).Build()
+		dir := GinkgoT().TempDir()
+		publisher, err := cloudevents.NewPublisher("http://localhost", "/usage-operator", dir)
+		Expect(err).ShouldNot(HaveOccurred())
+		usageTracker, err := NewUsageTracker(fakeClient)
+		Expect(err).ShouldNot(HaveOccurred())
+		// every attempt would start the interval at another time without a stable start
+		usageTracker.WithGranularity(time.Nanosecond).WithPublisher(publisher)
+
+		Expect(usageTracker.CreateOrUpdateEvent(ctx, projectName, workspaceName, conflictMCPName, "", "Ready")).Should(Succeed())
+		Expect(conflicts).Should(BeZero())
+
+		files, err := os.ReadDir(dir)
+		Expect(err).ShouldNot(HaveOccurred())
+		ids := map[string]struct{}{}
+		for _, file := range files {
+			data, err := os.ReadFile(filepath.Join(dir, file.Name()))
+			Expect(err).ShouldNot(HaveOccurred())
+			var event cloudevents.Event
+			Expect(json.Unmarshal(data, &event)).Should(Succeed())
+			if event.Type == cloudevents.TypeMCPCreated {
+				ids[event.ID] = struct{}{}
+			}
+		}
+		Expect(ids).Should(HaveLen(1))
+	})
+
+	It("should record events about the lifecycle of an mcp usage resource", func() {
+		ctx := context.Background()
+		eventMCPName := "mcp-event-test"
+
+		recorder := events.NewFakeRecorder(20)
+		usageTracker, err := NewUsageTracker(k8sClient)
+		Expect(err).ShouldNot(HaveOccurred())
+		usageTracker.WithEventRecorder(recorder)
+
+		Expect(usageTracker.CreateOrUpdateEvent(ctx, projectName, workspaceName, eventMCPName, "", "Ready")).Should(Succeed())
+		Expect(usageTracker.DeletionEvent(ctx, projectName, workspaceName, eventMCPName, "")).Should(Succeed())
+		Expect(usageTracker.CreateOrUpdateEvent(ctx, projectName, workspaceName, eventMCPName, "", "Ready")).Should(Succeed())
+
+		var recorded []string
+		for len(recorder.Events) > 0 {
+			recorded = append(recorded, <-recorder.Events)
+		}
+		Expect(recorded).Should(ContainElement(HavePrefix("Normal Created started tracking the usage of mcp " + eventMCPName)))
+		// the project of the test doesn't exist, so the charging target can't be resolved
+		Expect(recorded).Should(ContainElement(HavePrefix("Warning ChargingTargetResolutionFailed")))
+		Expect(recorded).Should(ContainElement(HavePrefix("Normal DeletionCaptured usage is final")))
+		Expect(recorded).Should(ContainElement("Normal Recreated mcp was re-created, started a new lifecycle interval"))
+	})
+
+	It("should roll up the months and freeze the closed ones", func() {
+		ctx := context.Background()
+
+		now := time.Now().UTC()
+		startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		previousMonth := startOfMonth.AddDate(0, -1, 0)
+		mcpUsage := v2.MCPUsage{
+			ObjectMeta: metav1.ObjectMeta{Name: "monthly-test"},
+			Spec:       v2.MCPUsageSpec{Project: projectName, Workspace: workspaceName, MCP: "mcp-monthly-test"},
+		}
+		Expect(k8sClient.Create(ctx, &mcpUsage)).Should(Succeed())
+		mcpUsage.Status.UsageOperator = v2.UsageOperatorStatus{
+			ChargingTarget:    "cc-1",
+			MCPCreatedAt:      metav1.NewTime(previousMonth),
+			LastUsageCaptured: metav1.NewTime(now),
+			Usage: []v2.DailyUsage{
+				{Date: metav1.NewTime(previousMonth), Usage: metav1.Duration{Duration: 24 * time.Hour}},
+				{Date: metav1.NewTime(startOfMonth), Usage: metav1.Duration{Duration: 4 * time.Hour}},
+			},
+		}
+		Expect(k8sClient.Status().Update(ctx, &mcpUsage)).Should(Succeed())
+
+		usageTracker, err := NewUsageTracker(k8sClient)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(usageTracker.GarbageCollection(ctx)).Should(Succeed())
+
+		var closed v2.MCPUsageMonthly
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Name: MonthlyName(mcpUsage.Name, previousMonth.Format("2006-01"))}, &closed)).Should(Succeed())
+		Expect(closed.Spec.MCP).Should(Equal("mcp-monthly-test"))
+		Expect(closed.Status.Final).Should(BeTrue())
+		Expect(closed.Status.Usage.Duration).Should(Equal(24 * time.Hour))
+		Expect(closed.Status.ChargingTargets).Should(HaveLen(1))
+
+		var open v2.MCPUsageMonthly
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Name: MonthlyName(mcpUsage.Name, startOfMonth.Format("2006-01"))}, &open)).Should(Succeed())
+		Expect(open.Status.Final).Should(BeFalse())
+		Expect(open.Status.Usage.Duration).Should(Equal(4 * time.Hour))
+
+		// a final month is immutable
+		closed.Status.Usage.Duration = time.Hour
+		Expect(k8sClient.Status().Update(ctx, &closed)).ShouldNot(Succeed())
 	})
 })